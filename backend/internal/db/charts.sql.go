@@ -13,20 +13,21 @@ import (
 
 const createApp = `-- name: CreateApp :one
 INSERT INTO apps (
-    chart_id, name, image, app_type, ports, configs, mounts
+    chart_id, name, image, app_type, ports, configs, mounts, profile_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
-) RETURNING id, chart_id, name, image, app_type, ports, configs, mounts, created_at
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, chart_id, name, image, app_type, ports, configs, mounts, created_at, profile_id
 `
 
 type CreateAppParams struct {
-	ChartID int32       `json:"chart_id"`
-	Name    string      `json:"name"`
-	Image   pgtype.Text `json:"image"`
-	AppType pgtype.Text `json:"app_type"`
-	Ports   pgtype.Text `json:"ports"`
-	Configs pgtype.Text `json:"configs"`
-	Mounts  pgtype.Text `json:"mounts"`
+	ChartID   int32       `json:"chart_id"`
+	Name      string      `json:"name"`
+	Image     pgtype.Text `json:"image"`
+	AppType   pgtype.Text `json:"app_type"`
+	Ports     pgtype.Text `json:"ports"`
+	Configs   pgtype.Text `json:"configs"`
+	Mounts    pgtype.Text `json:"mounts"`
+	ProfileID pgtype.Int4 `json:"profile_id"`
 }
 
 func (q *Queries) CreateApp(ctx context.Context, arg CreateAppParams) (App, error) {
@@ -38,6 +39,7 @@ func (q *Queries) CreateApp(ctx context.Context, arg CreateAppParams) (App, erro
 		arg.Ports,
 		arg.Configs,
 		arg.Mounts,
+		arg.ProfileID,
 	)
 	var i App
 	err := row.Scan(
@@ -50,28 +52,34 @@ func (q *Queries) CreateApp(ctx context.Context, arg CreateAppParams) (App, erro
 		&i.Configs,
 		&i.Mounts,
 		&i.CreatedAt,
+		&i.ProfileID,
 	)
 	return i, err
 }
 
 const createChart = `-- name: CreateChart :one
 INSERT INTO charts (
-    name, version, description, type, chart_url, image_tag, canary_tag, manifest, is_latest
+    name, version, description, type, chart_url, image_tag, canary_tag, manifest, is_latest, kube_version, api_versions,
+    vendored, security_summary
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
-) RETURNING id, name, version, description, type, chart_url, image_tag, canary_tag, manifest, is_latest, created_at, updated_at, ingress_paths, container_images, service_ports, manifest_parsed_at
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+) RETURNING id, name, version, description, type, chart_url, image_tag, canary_tag, manifest, is_latest, created_at, updated_at, ingress_paths, container_images, service_ports, manifest_parsed_at, kube_version, api_versions, vendored, security_summary
 `
 
 type CreateChartParams struct {
-	Name        string      `json:"name"`
-	Version     string      `json:"version"`
-	Description pgtype.Text `json:"description"`
-	Type        string      `json:"type"`
-	ChartUrl    string      `json:"chart_url"`
-	ImageTag    pgtype.Text `json:"image_tag"`
-	CanaryTag   pgtype.Text `json:"canary_tag"`
-	Manifest    pgtype.Text `json:"manifest"`
-	IsLatest    pgtype.Bool `json:"is_latest"`
+	Name            string      `json:"name"`
+	Version         string      `json:"version"`
+	Description     pgtype.Text `json:"description"`
+	Type            string      `json:"type"`
+	ChartUrl        string      `json:"chart_url"`
+	ImageTag        pgtype.Text `json:"image_tag"`
+	CanaryTag       pgtype.Text `json:"canary_tag"`
+	Manifest        pgtype.Text `json:"manifest"`
+	IsLatest        pgtype.Bool `json:"is_latest"`
+	KubeVersion     pgtype.Text `json:"kube_version"`
+	ApiVersions     pgtype.Text `json:"api_versions"`
+	Vendored        bool        `json:"vendored"`
+	SecuritySummary pgtype.Text `json:"security_summary"`
 }
 
 func (q *Queries) CreateChart(ctx context.Context, arg CreateChartParams) (Chart, error) {
@@ -85,6 +93,10 @@ func (q *Queries) CreateChart(ctx context.Context, arg CreateChartParams) (Chart
 		arg.CanaryTag,
 		arg.Manifest,
 		arg.IsLatest,
+		arg.KubeVersion,
+		arg.ApiVersions,
+		arg.Vendored,
+		arg.SecuritySummary,
 	)
 	var i Chart
 	err := row.Scan(
@@ -104,16 +116,21 @@ func (q *Queries) CreateChart(ctx context.Context, arg CreateChartParams) (Chart
 		&i.ContainerImages,
 		&i.ServicePorts,
 		&i.ManifestParsedAt,
+		&i.KubeVersion,
+		&i.ApiVersions,
+		&i.Vendored,
+		&i.SecuritySummary,
 	)
 	return i, err
 }
 
 const createDependency = `-- name: CreateDependency :one
 INSERT INTO dependencies (
-    chart_id, dependency_name, dependency_version, repository, condition_field
+    chart_id, dependency_name, dependency_version, repository, condition_field, image_tag, canary_tag, enabled, tags,
+    resolved_version, alias, security_summary
 ) VALUES (
-    $1, $2, $3, $4, $5
-) RETURNING id, chart_id, dependency_name, dependency_version, repository, condition_field, image_tag, canary_tag, created_at
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+) RETURNING id, chart_id, dependency_name, dependency_version, repository, condition_field, image_tag, canary_tag, created_at, enabled, tags, resolved_version, alias, security_summary
 `
 
 type CreateDependencyParams struct {
@@ -122,6 +139,13 @@ type CreateDependencyParams struct {
 	DependencyVersion string      `json:"dependency_version"`
 	Repository        pgtype.Text `json:"repository"`
 	ConditionField    pgtype.Text `json:"condition_field"`
+	ImageTag          pgtype.Text `json:"image_tag"`
+	CanaryTag         pgtype.Text `json:"canary_tag"`
+	Enabled           bool        `json:"enabled"`
+	Tags              pgtype.Text `json:"tags"`
+	ResolvedVersion   pgtype.Text `json:"resolved_version"`
+	Alias             string      `json:"alias"`
+	SecuritySummary   pgtype.Text `json:"security_summary"`
 }
 
 func (q *Queries) CreateDependency(ctx context.Context, arg CreateDependencyParams) (Dependency, error) {
@@ -131,6 +155,13 @@ func (q *Queries) CreateDependency(ctx context.Context, arg CreateDependencyPara
 		arg.DependencyVersion,
 		arg.Repository,
 		arg.ConditionField,
+		arg.ImageTag,
+		arg.CanaryTag,
+		arg.Enabled,
+		arg.Tags,
+		arg.ResolvedVersion,
+		arg.Alias,
+		arg.SecuritySummary,
 	)
 	var i Dependency
 	err := row.Scan(
@@ -143,6 +174,11 @@ func (q *Queries) CreateDependency(ctx context.Context, arg CreateDependencyPara
 		&i.ImageTag,
 		&i.CanaryTag,
 		&i.CreatedAt,
+		&i.Enabled,
+		&i.Tags,
+		&i.ResolvedVersion,
+		&i.Alias,
+		&i.SecuritySummary,
 	)
 	return i, err
 }
@@ -157,7 +193,7 @@ func (q *Queries) DeleteChart(ctx context.Context, name string) error {
 }
 
 const deleteChartApps = `-- name: DeleteChartApps :exec
-DELETE FROM apps WHERE chart_id = $1
+DELETE FROM apps WHERE chart_id = $1 AND profile_id IS NULL
 `
 
 func (q *Queries) DeleteChartApps(ctx context.Context, chartID int32) error {
@@ -222,7 +258,7 @@ func (q *Queries) FetchChartDependencies(ctx context.Context, name string) ([]Fe
 }
 
 const getChart = `-- name: GetChart :one
-SELECT id, name, version, description, type, chart_url, image_tag, canary_tag, manifest, is_latest, created_at, updated_at, ingress_paths, container_images, service_ports, manifest_parsed_at FROM charts WHERE name = $1 AND is_latest = TRUE LIMIT 1
+SELECT id, name, version, description, type, chart_url, image_tag, canary_tag, manifest, is_latest, created_at, updated_at, ingress_paths, container_images, service_ports, manifest_parsed_at, kube_version, api_versions, vendored, security_summary FROM charts WHERE name = $1 AND is_latest = TRUE LIMIT 1
 `
 
 func (q *Queries) GetChart(ctx context.Context, name string) (Chart, error) {
@@ -245,12 +281,16 @@ func (q *Queries) GetChart(ctx context.Context, name string) (Chart, error) {
 		&i.ContainerImages,
 		&i.ServicePorts,
 		&i.ManifestParsedAt,
+		&i.KubeVersion,
+		&i.ApiVersions,
+		&i.Vendored,
+		&i.SecuritySummary,
 	)
 	return i, err
 }
 
 const getChartApps = `-- name: GetChartApps :many
-SELECT id, chart_id, name, image, app_type, ports, configs, mounts, created_at FROM apps WHERE chart_id = $1
+SELECT id, chart_id, name, image, app_type, ports, configs, mounts, created_at, profile_id FROM apps WHERE chart_id = $1 AND profile_id IS NULL
 `
 
 func (q *Queries) GetChartApps(ctx context.Context, chartID int32) ([]App, error) {
@@ -272,6 +312,7 @@ func (q *Queries) GetChartApps(ctx context.Context, chartID int32) ([]App, error
 			&i.Configs,
 			&i.Mounts,
 			&i.CreatedAt,
+			&i.ProfileID,
 		); err != nil {
 			return nil, err
 		}
@@ -284,7 +325,7 @@ func (q *Queries) GetChartApps(ctx context.Context, chartID int32) ([]App, error
 }
 
 const getChartByID = `-- name: GetChartByID :one
-SELECT id, name, version, description, type, chart_url, image_tag, canary_tag, manifest, is_latest, created_at, updated_at, ingress_paths, container_images, service_ports, manifest_parsed_at FROM charts WHERE id = $1 LIMIT 1
+SELECT id, name, version, description, type, chart_url, image_tag, canary_tag, manifest, is_latest, created_at, updated_at, ingress_paths, container_images, service_ports, manifest_parsed_at, kube_version, api_versions, vendored, security_summary FROM charts WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetChartByID(ctx context.Context, id int32) (Chart, error) {
@@ -307,12 +348,16 @@ func (q *Queries) GetChartByID(ctx context.Context, id int32) (Chart, error) {
 		&i.ContainerImages,
 		&i.ServicePorts,
 		&i.ManifestParsedAt,
+		&i.KubeVersion,
+		&i.ApiVersions,
+		&i.Vendored,
+		&i.SecuritySummary,
 	)
 	return i, err
 }
 
 const getChartDependencies = `-- name: GetChartDependencies :many
-SELECT d.id, d.chart_id, d.dependency_name, d.dependency_version, d.repository, d.condition_field, d.image_tag, d.canary_tag, d.created_at, c.name as chart_name FROM dependencies d
+SELECT d.id, d.chart_id, d.dependency_name, d.dependency_version, d.repository, d.condition_field, d.image_tag, d.canary_tag, d.created_at, d.enabled, d.tags, d.resolved_version, d.alias, d.security_summary, c.name as chart_name FROM dependencies d
 JOIN charts c ON d.chart_id = c.id
 WHERE d.chart_id = $1
 `
@@ -327,6 +372,11 @@ type GetChartDependenciesRow struct {
 	ImageTag          pgtype.Text      `json:"image_tag"`
 	CanaryTag         pgtype.Text      `json:"canary_tag"`
 	CreatedAt         pgtype.Timestamp `json:"created_at"`
+	Enabled           bool             `json:"enabled"`
+	Tags              pgtype.Text      `json:"tags"`
+	ResolvedVersion   pgtype.Text      `json:"resolved_version"`
+	Alias             string           `json:"alias"`
+	SecuritySummary   pgtype.Text      `json:"security_summary"`
 	ChartName         string           `json:"chart_name"`
 }
 
@@ -349,6 +399,11 @@ func (q *Queries) GetChartDependencies(ctx context.Context, chartID int32) ([]Ge
 			&i.ImageTag,
 			&i.CanaryTag,
 			&i.CreatedAt,
+			&i.Enabled,
+			&i.Tags,
+			&i.ResolvedVersion,
+			&i.Alias,
+			&i.SecuritySummary,
 			&i.ChartName,
 		); err != nil {
 			return nil, err
@@ -362,7 +417,7 @@ func (q *Queries) GetChartDependencies(ctx context.Context, chartID int32) ([]Ge
 }
 
 const getChartVersion = `-- name: GetChartVersion :one
-SELECT id, name, version, description, type, chart_url, image_tag, canary_tag, manifest, is_latest, created_at, updated_at, ingress_paths, container_images, service_ports, manifest_parsed_at FROM charts WHERE name = $1 AND version = $2 LIMIT 1
+SELECT id, name, version, description, type, chart_url, image_tag, canary_tag, manifest, is_latest, created_at, updated_at, ingress_paths, container_images, service_ports, manifest_parsed_at, kube_version, api_versions, vendored, security_summary FROM charts WHERE name = $1 AND version = $2 LIMIT 1
 `
 
 type GetChartVersionParams struct {
@@ -390,12 +445,61 @@ func (q *Queries) GetChartVersion(ctx context.Context, arg GetChartVersionParams
 		&i.ContainerImages,
 		&i.ServicePorts,
 		&i.ManifestParsedAt,
+		&i.KubeVersion,
+		&i.ApiVersions,
+		&i.Vendored,
+		&i.SecuritySummary,
 	)
 	return i, err
 }
 
+const listAllChartVersions = `-- name: ListAllChartVersions :many
+SELECT id, name, version, description, type, chart_url, image_tag, canary_tag, manifest, is_latest, created_at, updated_at, ingress_paths, container_images, service_ports, manifest_parsed_at, kube_version, api_versions, vendored, security_summary FROM charts ORDER BY name ASC, created_at DESC
+`
+
+func (q *Queries) ListAllChartVersions(ctx context.Context) ([]Chart, error) {
+	rows, err := q.db.Query(ctx, listAllChartVersions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chart
+	for rows.Next() {
+		var i Chart
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Version,
+			&i.Description,
+			&i.Type,
+			&i.ChartUrl,
+			&i.ImageTag,
+			&i.CanaryTag,
+			&i.Manifest,
+			&i.IsLatest,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.IngressPaths,
+			&i.ContainerImages,
+			&i.ServicePorts,
+			&i.ManifestParsedAt,
+			&i.KubeVersion,
+			&i.ApiVersions,
+			&i.Vendored,
+			&i.SecuritySummary,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listChartVersions = `-- name: ListChartVersions :many
-SELECT id, name, version, description, type, chart_url, image_tag, canary_tag, manifest, is_latest, created_at, updated_at, ingress_paths, container_images, service_ports, manifest_parsed_at FROM charts WHERE name = $1 ORDER BY created_at DESC
+SELECT id, name, version, description, type, chart_url, image_tag, canary_tag, manifest, is_latest, created_at, updated_at, ingress_paths, container_images, service_ports, manifest_parsed_at, kube_version, api_versions, vendored, security_summary FROM charts WHERE name = $1 ORDER BY created_at DESC
 `
 
 func (q *Queries) ListChartVersions(ctx context.Context, name string) ([]Chart, error) {
@@ -424,6 +528,10 @@ func (q *Queries) ListChartVersions(ctx context.Context, name string) ([]Chart,
 			&i.ContainerImages,
 			&i.ServicePorts,
 			&i.ManifestParsedAt,
+			&i.KubeVersion,
+			&i.ApiVersions,
+			&i.Vendored,
+			&i.SecuritySummary,
 		); err != nil {
 			return nil, err
 		}
@@ -436,7 +544,7 @@ func (q *Queries) ListChartVersions(ctx context.Context, name string) ([]Chart,
 }
 
 const listCharts = `-- name: ListCharts :many
-SELECT id, name, version, description, type, chart_url, image_tag, canary_tag, manifest, is_latest, created_at, updated_at, ingress_paths, container_images, service_ports, manifest_parsed_at FROM charts WHERE is_latest = TRUE ORDER BY updated_at DESC
+SELECT id, name, version, description, type, chart_url, image_tag, canary_tag, manifest, is_latest, created_at, updated_at, ingress_paths, container_images, service_ports, manifest_parsed_at, kube_version, api_versions, vendored, security_summary FROM charts WHERE is_latest = TRUE ORDER BY updated_at DESC
 `
 
 func (q *Queries) ListCharts(ctx context.Context) ([]Chart, error) {
@@ -465,6 +573,10 @@ func (q *Queries) ListCharts(ctx context.Context) ([]Chart, error) {
 			&i.ContainerImages,
 			&i.ServicePorts,
 			&i.ManifestParsedAt,
+			&i.KubeVersion,
+			&i.ApiVersions,
+			&i.Vendored,
+			&i.SecuritySummary,
 		); err != nil {
 			return nil, err
 		}
@@ -477,7 +589,7 @@ func (q *Queries) ListCharts(ctx context.Context) ([]Chart, error) {
 }
 
 const searchCharts = `-- name: SearchCharts :many
-SELECT id, name, version, description, type, chart_url, image_tag, canary_tag, manifest, is_latest, created_at, updated_at, ingress_paths, container_images, service_ports, manifest_parsed_at FROM charts 
+SELECT id, name, version, description, type, chart_url, image_tag, canary_tag, manifest, is_latest, created_at, updated_at, ingress_paths, container_images, service_ports, manifest_parsed_at, kube_version, api_versions, vendored, security_summary FROM charts 
 WHERE name LIKE $1 OR description LIKE $2
 ORDER BY updated_at DESC
 `
@@ -513,6 +625,10 @@ func (q *Queries) SearchCharts(ctx context.Context, arg SearchChartsParams) ([]C
 			&i.ContainerImages,
 			&i.ServicePorts,
 			&i.ManifestParsedAt,
+			&i.KubeVersion,
+			&i.ApiVersions,
+			&i.Vendored,
+			&i.SecuritySummary,
 		); err != nil {
 			return nil, err
 		}
@@ -550,21 +666,26 @@ func (q *Queries) SetVersionAsLatest(ctx context.Context, arg SetVersionAsLatest
 const updateChart = `-- name: UpdateChart :one
 UPDATE charts 
 SET version = $1, description = $2, type = $3, chart_url = $4, 
-    image_tag = $5, canary_tag = $6, manifest = $7, updated_at = CURRENT_TIMESTAMP
-WHERE name = $8 AND version = $9
-RETURNING id, name, version, description, type, chart_url, image_tag, canary_tag, manifest, is_latest, created_at, updated_at, ingress_paths, container_images, service_ports, manifest_parsed_at
+    image_tag = $5, canary_tag = $6, manifest = $7, kube_version = $8,
+    api_versions = $9, vendored = $12, security_summary = $13, updated_at = CURRENT_TIMESTAMP
+WHERE name = $10 AND version = $11
+RETURNING id, name, version, description, type, chart_url, image_tag, canary_tag, manifest, is_latest, created_at, updated_at, ingress_paths, container_images, service_ports, manifest_parsed_at, kube_version, api_versions, vendored, security_summary
 `
 
 type UpdateChartParams struct {
-	Version     string      `json:"version"`
-	Description pgtype.Text `json:"description"`
-	Type        string      `json:"type"`
-	ChartUrl    string      `json:"chart_url"`
-	ImageTag    pgtype.Text `json:"image_tag"`
-	CanaryTag   pgtype.Text `json:"canary_tag"`
-	Manifest    pgtype.Text `json:"manifest"`
-	Name        string      `json:"name"`
-	Version_2   string      `json:"version_2"`
+	Version         string      `json:"version"`
+	Description     pgtype.Text `json:"description"`
+	Type            string      `json:"type"`
+	ChartUrl        string      `json:"chart_url"`
+	ImageTag        pgtype.Text `json:"image_tag"`
+	CanaryTag       pgtype.Text `json:"canary_tag"`
+	Manifest        pgtype.Text `json:"manifest"`
+	KubeVersion     pgtype.Text `json:"kube_version"`
+	ApiVersions     pgtype.Text `json:"api_versions"`
+	Name            string      `json:"name"`
+	Version_2       string      `json:"version_2"`
+	Vendored        bool        `json:"vendored"`
+	SecuritySummary pgtype.Text `json:"security_summary"`
 }
 
 func (q *Queries) UpdateChart(ctx context.Context, arg UpdateChartParams) (Chart, error) {
@@ -576,8 +697,12 @@ func (q *Queries) UpdateChart(ctx context.Context, arg UpdateChartParams) (Chart
 		arg.ImageTag,
 		arg.CanaryTag,
 		arg.Manifest,
+		arg.KubeVersion,
+		arg.ApiVersions,
 		arg.Name,
 		arg.Version_2,
+		arg.Vendored,
+		arg.SecuritySummary,
 	)
 	var i Chart
 	err := row.Scan(
@@ -597,6 +722,10 @@ func (q *Queries) UpdateChart(ctx context.Context, arg UpdateChartParams) (Chart
 		&i.ContainerImages,
 		&i.ServicePorts,
 		&i.ManifestParsedAt,
+		&i.KubeVersion,
+		&i.ApiVersions,
+		&i.Vendored,
+		&i.SecuritySummary,
 	)
 	return i, err
 }
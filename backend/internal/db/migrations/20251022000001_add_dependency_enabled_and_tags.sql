@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Whether the dependency's condition/tags evaluated to enabled for the rendered values
+ALTER TABLE dependencies ADD COLUMN enabled BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE dependencies ADD COLUMN tags TEXT; -- JSON array of dependency tags
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE dependencies DROP COLUMN IF EXISTS tags;
+ALTER TABLE dependencies DROP COLUMN IF EXISTS enabled;
+
+-- +goose StatementEnd
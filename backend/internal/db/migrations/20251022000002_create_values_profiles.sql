@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Named values profiles (environments) saved per chart name
+CREATE TABLE values_profiles (
+    id SERIAL PRIMARY KEY,
+    chart_name TEXT NOT NULL,
+    name TEXT NOT NULL,
+    values_files TEXT, -- JSON array of values file paths, later files win
+    set_values TEXT, -- JSON array of --set overrides
+    use_host_network BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(chart_name, name)
+);
+
+-- Rendered result of a chart version under a values profile
+CREATE TABLE chart_renders (
+    id SERIAL PRIMARY KEY,
+    chart_id INTEGER NOT NULL,
+    profile_id INTEGER NOT NULL,
+    manifest TEXT,
+    image_tag TEXT,
+    canary_tag TEXT,
+    container_images TEXT, -- JSON array of container images
+    ingress_paths TEXT, -- JSON array of ingress paths
+    service_ports TEXT, -- JSON array of service ports
+    disabled_dependencies TEXT, -- JSON array of dependency names disabled by this profile
+    rendered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (chart_id) REFERENCES charts (id) ON DELETE CASCADE,
+    FOREIGN KEY (profile_id) REFERENCES values_profiles (id) ON DELETE CASCADE,
+    UNIQUE(chart_id, profile_id)
+);
+
+-- Apps parsed under a profile; NULL for the default values
+ALTER TABLE apps ADD COLUMN profile_id INTEGER REFERENCES values_profiles (id) ON DELETE CASCADE;
+
+CREATE INDEX idx_values_profiles_chart_name ON values_profiles(chart_name);
+CREATE INDEX idx_chart_renders_chart_id ON chart_renders(chart_id);
+CREATE INDEX idx_apps_profile_id ON apps(profile_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_apps_profile_id;
+DROP INDEX IF EXISTS idx_chart_renders_chart_id;
+DROP INDEX IF EXISTS idx_values_profiles_chart_name;
+ALTER TABLE apps DROP COLUMN IF EXISTS profile_id;
+DROP TABLE IF EXISTS chart_renders;
+DROP TABLE IF EXISTS values_profiles;
+
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Container images found in rendered workloads, one row per container
+CREATE TABLE images (
+    id SERIAL PRIMARY KEY,
+    chart_id INTEGER NOT NULL,
+    profile_id INTEGER, -- NULL for the default values
+    image TEXT NOT NULL, -- reference as written in the manifest
+    registry TEXT NOT NULL,
+    repository TEXT NOT NULL,
+    tag TEXT,
+    digest TEXT,
+    chart_version TEXT NOT NULL,
+    workload TEXT,
+    workload_kind TEXT,
+    container_name TEXT,
+    init_container BOOLEAN NOT NULL DEFAULT FALSE,
+    subchart TEXT, -- path of the subchart that rendered it, NULL for the chart itself
+    enabled BOOLEAN NOT NULL DEFAULT TRUE, -- FALSE when the subchart is disabled by condition/tags
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (chart_id) REFERENCES charts (id) ON DELETE CASCADE,
+    FOREIGN KEY (profile_id) REFERENCES values_profiles (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_images_chart_id ON images(chart_id);
+CREATE INDEX idx_images_repository_tag ON images(repository, tag);
+CREATE INDEX idx_images_digest ON images(digest);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_images_digest;
+DROP INDEX IF EXISTS idx_images_repository_tag;
+DROP INDEX IF EXISTS idx_images_chart_id;
+DROP TABLE IF EXISTS images;
+
+-- +goose StatementEnd
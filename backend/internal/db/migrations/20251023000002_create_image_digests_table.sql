@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Registry-resolved digest of each image tag, refreshed on every sync
+CREATE TABLE image_digests (
+    id SERIAL PRIMARY KEY,
+    registry TEXT NOT NULL,
+    repository TEXT NOT NULL,
+    tag TEXT NOT NULL,
+    digest TEXT NOT NULL,
+    media_type TEXT,
+    platforms TEXT, -- JSON array of os/arch[/variant]
+    size_bytes BIGINT,
+    image_created_at TIMESTAMP,
+    previous_digest TEXT, -- digest the tag pointed to before it last moved
+    digest_changed BOOLEAN NOT NULL DEFAULT FALSE, -- tag moved since the previous sync
+    resolved_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(registry, repository, tag)
+);
+
+CREATE INDEX idx_image_digests_digest ON image_digests(digest);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_image_digests_digest;
+DROP TABLE IF EXISTS image_digests;
+
+-- +goose StatementEnd
@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- One scanner report per image, keyed by reference and/or digest
+CREATE TABLE image_scans (
+    id SERIAL PRIMARY KEY,
+    image TEXT NOT NULL, -- normalized reference the report was submitted for
+    registry TEXT, -- NULL when the report is keyed by digest only
+    repository TEXT,
+    tag TEXT,
+    digest TEXT,
+    scanner TEXT NOT NULL, -- trivy or grype
+    scanned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(image, scanner)
+);
+
+CREATE TABLE vulnerabilities (
+    id SERIAL PRIMARY KEY,
+    scan_id INTEGER NOT NULL,
+    vulnerability_id TEXT NOT NULL,
+    package_name TEXT NOT NULL,
+    installed_version TEXT,
+    fixed_version TEXT,
+    severity TEXT NOT NULL, -- CRITICAL, HIGH, MEDIUM, LOW or UNKNOWN
+    title TEXT,
+    FOREIGN KEY (scan_id) REFERENCES image_scans (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_image_scans_repository_tag ON image_scans(repository, tag);
+CREATE INDEX idx_image_scans_digest ON image_scans(digest);
+CREATE INDEX idx_vulnerabilities_scan_id ON vulnerabilities(scan_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_vulnerabilities_scan_id;
+DROP INDEX IF EXISTS idx_image_scans_digest;
+DROP INDEX IF EXISTS idx_image_scans_repository_tag;
+DROP TABLE IF EXISTS vulnerabilities;
+DROP TABLE IF EXISTS image_scans;
+
+-- +goose StatementEnd
@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Declarative lint rules checked against every fetched chart
+CREATE TABLE policies (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT,
+    rule_type TEXT NOT NULL, -- built-in check the rule configures, see pkg/policies.go
+    params TEXT, -- JSON object of rule parameters
+    action TEXT NOT NULL DEFAULT 'warn', -- warn or deny
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE policy_violations (
+    id SERIAL PRIMARY KEY,
+    chart_id INTEGER NOT NULL,
+    policy_id INTEGER NOT NULL,
+    policy_name TEXT NOT NULL,
+    action TEXT NOT NULL,
+    resource TEXT, -- offending workload/container or dependency
+    message TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (chart_id) REFERENCES charts (id) ON DELETE CASCADE,
+    FOREIGN KEY (policy_id) REFERENCES policies (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_policy_violations_chart_id ON policy_violations(chart_id);
+
+INSERT INTO policies (name, description, rule_type, params, action) VALUES
+    ('no-latest-tag', 'Images must not use the latest tag', 'disallowed-image-tags', '{"tags": ["latest"]}', 'warn'),
+    ('allowed-registries', 'Images must come from an allowed registry', 'allowed-registries', '{"registries": ["docker.io", "ghcr.io", "quay.io", "registry.k8s.io"]}', 'warn'),
+    ('resource-limits', 'Containers must set CPU and memory limits', 'required-resource-limits', '{"resources": ["cpu", "memory"]}', 'warn'),
+    ('no-privileged', 'Containers must not run privileged', 'disallow-privileged', '{}', 'warn'),
+    ('chart-api-v2', 'Charts must use apiVersion v2', 'allowed-chart-api-versions', '{"apiVersions": ["v2"]}', 'warn'),
+    ('pinned-dependencies', 'Dependencies must be pinned to an exact version', 'pinned-dependencies', '{}', 'warn');
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_policy_violations_chart_id;
+DROP TABLE IF EXISTS policy_violations;
+DROP TABLE IF EXISTS policies;
+
+-- +goose StatementEnd
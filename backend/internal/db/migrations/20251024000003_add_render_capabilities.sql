@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Kubernetes version and extra API versions a manifest was rendered against,
+-- NULL when Helm's default capabilities were used
+ALTER TABLE charts ADD COLUMN kube_version TEXT;
+ALTER TABLE charts ADD COLUMN api_versions TEXT; -- JSON array
+
+ALTER TABLE chart_renders ADD COLUMN kube_version TEXT;
+ALTER TABLE chart_renders ADD COLUMN api_versions TEXT;
+
+ALTER TABLE values_profiles ADD COLUMN kube_version TEXT;
+ALTER TABLE values_profiles ADD COLUMN api_versions TEXT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE values_profiles DROP COLUMN IF EXISTS api_versions;
+ALTER TABLE values_profiles DROP COLUMN IF EXISTS kube_version;
+ALTER TABLE chart_renders DROP COLUMN IF EXISTS api_versions;
+ALTER TABLE chart_renders DROP COLUMN IF EXISTS kube_version;
+ALTER TABLE charts DROP COLUMN IF EXISTS api_versions;
+ALTER TABLE charts DROP COLUMN IF EXISTS kube_version;
+
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Chart imports that failed to template, or templated but could not be
+-- parsed into apps, kept with their inputs so they can be retried
+CREATE TABLE chart_parse_attempts (
+    id SERIAL PRIMARY KEY,
+    chart_url TEXT NOT NULL,
+    chart_name TEXT,
+    chart_version TEXT, -- set for partial results only
+    values_path TEXT,
+    set_values TEXT, -- JSON array
+    use_host_network BOOLEAN NOT NULL DEFAULT FALSE,
+    kube_version TEXT,
+    api_versions TEXT, -- JSON array
+    status TEXT NOT NULL DEFAULT 'failed', -- failed, partial or resolved
+    stage TEXT NOT NULL, -- template or parse
+    category TEXT NOT NULL, -- auth, not_found, template, schema_validation, panic or unknown
+    message TEXT NOT NULL,
+    template_file TEXT,
+    template_line INTEGER,
+    stack TEXT, -- goroutine stack of a recovered panic
+    retries INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_chart_parse_attempts_status ON chart_parse_attempts(status);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS chart_parse_attempts;
+
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Default values and values.schema.json shipped with each chart version
+CREATE TABLE chart_values (
+    chart_id INTEGER PRIMARY KEY,
+    default_values TEXT, -- JSON object of the chart's values.yaml
+    values_schema TEXT, -- values.schema.json, NULL when the chart ships none
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (chart_id) REFERENCES charts (id) ON DELETE CASCADE
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS chart_values;
+
+-- +goose StatementEnd
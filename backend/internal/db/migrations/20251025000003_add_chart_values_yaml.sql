@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- values.yaml as shipped, comments included, for generating values docs
+ALTER TABLE chart_values ADD COLUMN values_yaml TEXT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE chart_values DROP COLUMN IF EXISTS values_yaml;
+
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Versions explicitly pinned as latest, overriding semver precedence
+CREATE TABLE chart_pins (
+    chart_name TEXT PRIMARY KEY,
+    version TEXT NOT NULL,
+    pinned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS chart_pins;
+
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- The version a dependency's Chart.yaml range resolved to: the vendored
+-- subchart's version, or the one pulled from its repository
+ALTER TABLE dependencies ADD COLUMN resolved_version TEXT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE dependencies DROP COLUMN IF EXISTS resolved_version;
+
+-- +goose StatementEnd
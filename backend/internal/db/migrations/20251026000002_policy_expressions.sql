@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Policies are CEL expressions that must hold for every rendered object, or
+-- once for the chart itself, instead of configurable built-in rule types
+ALTER TABLE policies ADD COLUMN expression TEXT;
+ALTER TABLE policies ADD COLUMN scope TEXT NOT NULL DEFAULT 'object'; -- object or chart
+ALTER TABLE policies ADD COLUMN message TEXT; -- reported for every object failing the expression
+
+UPDATE policies SET expression = 'images.all(i, i.digest != "" || i.tag != "latest")',
+    message = 'Images must not use the latest tag' WHERE name = 'no-latest-tag';
+UPDATE policies SET expression = 'images.all(i, i.registry in ["docker.io", "ghcr.io", "quay.io", "registry.k8s.io"])',
+    message = 'Image is not from an allowed registry' WHERE name = 'allowed-registries';
+UPDATE policies SET expression = 'containers.all(c, has(c.resources) && has(c.resources.limits) && "cpu" in c.resources.limits && "memory" in c.resources.limits)',
+    message = 'Every container must set CPU and memory limits' WHERE name = 'resource-limits';
+UPDATE policies SET expression = 'containers.all(c, !has(c.securityContext) || !has(c.securityContext.privileged) || c.securityContext.privileged != true)',
+    message = 'Containers must not run privileged' WHERE name = 'no-privileged';
+UPDATE policies SET expression = 'chart.apiVersion == "v2"', scope = 'chart',
+    message = 'Chart must use apiVersion v2' WHERE name = 'chart-api-v2';
+UPDATE policies SET expression = 'chart.dependencies.all(d, d.version.matches(r"^v?\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$"))', scope = 'chart',
+    message = 'Dependencies must be pinned to an exact version' WHERE name = 'pinned-dependencies';
+
+-- Other rule types can't be translated, keep them disabled until rewritten
+UPDATE policies SET expression = 'true', enabled = FALSE WHERE expression IS NULL;
+
+ALTER TABLE policies ALTER COLUMN expression SET NOT NULL;
+ALTER TABLE policies DROP COLUMN rule_type;
+ALTER TABLE policies DROP COLUMN params;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE policies ADD COLUMN rule_type TEXT NOT NULL DEFAULT '';
+ALTER TABLE policies ADD COLUMN params TEXT;
+
+UPDATE policies SET rule_type = 'disallowed-image-tags', params = '{"tags": ["latest"]}' WHERE name = 'no-latest-tag';
+UPDATE policies SET rule_type = 'allowed-registries', params = '{"registries": ["docker.io", "ghcr.io", "quay.io", "registry.k8s.io"]}' WHERE name = 'allowed-registries';
+UPDATE policies SET rule_type = 'required-resource-limits', params = '{"resources": ["cpu", "memory"]}' WHERE name = 'resource-limits';
+UPDATE policies SET rule_type = 'disallow-privileged', params = '{}' WHERE name = 'no-privileged';
+UPDATE policies SET rule_type = 'allowed-chart-api-versions', params = '{"apiVersions": ["v2"]}' WHERE name = 'chart-api-v2';
+UPDATE policies SET rule_type = 'pinned-dependencies', params = '{}' WHERE name = 'pinned-dependencies';
+DELETE FROM policies WHERE rule_type = '';
+
+ALTER TABLE policies ALTER COLUMN rule_type DROP DEFAULT;
+ALTER TABLE policies DROP COLUMN message;
+ALTER TABLE policies DROP COLUMN scope;
+ALTER TABLE policies DROP COLUMN expression;
+
+-- +goose StatementEnd
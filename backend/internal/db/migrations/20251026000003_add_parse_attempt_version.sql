@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- The version the import asked for, so retrying a history import pulls the
+-- same version instead of the newest
+ALTER TABLE chart_parse_attempts ADD COLUMN requested_version TEXT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE chart_parse_attempts DROP COLUMN IF EXISTS requested_version;
+
+-- +goose StatementEnd
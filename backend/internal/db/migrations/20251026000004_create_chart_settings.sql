@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Per-chart settings; include_prereleases lets a prerelease become latest
+-- even when stable versions are stored
+CREATE TABLE chart_settings (
+    chart_name TEXT PRIMARY KEY,
+    include_prereleases BOOLEAN NOT NULL DEFAULT false,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS chart_settings;
+
+-- +goose StatementEnd
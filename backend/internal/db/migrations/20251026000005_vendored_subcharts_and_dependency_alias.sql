@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Rows stored only because a parent vendors them under charts/. Fetching the
+-- chart directly clears the flag, and a parent never overwrites such a row.
+ALTER TABLE charts ADD COLUMN vendored BOOLEAN NOT NULL DEFAULT false;
+
+-- The same chart can be a dependency several times under different aliases
+ALTER TABLE dependencies ADD COLUMN alias TEXT NOT NULL DEFAULT '';
+ALTER TABLE dependencies DROP CONSTRAINT IF EXISTS dependencies_chart_id_dependency_name_key;
+ALTER TABLE dependencies ADD CONSTRAINT dependencies_chart_id_dependency_name_alias_key UNIQUE (chart_id, dependency_name, alias);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DELETE FROM dependencies WHERE alias <> '';
+ALTER TABLE dependencies DROP CONSTRAINT IF EXISTS dependencies_chart_id_dependency_name_alias_key;
+ALTER TABLE dependencies ADD CONSTRAINT dependencies_chart_id_dependency_name_key UNIQUE (chart_id, dependency_name);
+ALTER TABLE dependencies DROP COLUMN IF EXISTS alias;
+ALTER TABLE charts DROP COLUMN IF EXISTS vendored;
+
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Security summaries as JSON, computed when a chart is stored or rendered so
+-- listing charts doesn't analyze every manifest again
+ALTER TABLE charts ADD COLUMN security_summary TEXT;
+ALTER TABLE chart_renders ADD COLUMN security_summary TEXT;
+ALTER TABLE dependencies ADD COLUMN security_summary TEXT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE dependencies DROP COLUMN IF EXISTS security_summary;
+ALTER TABLE chart_renders DROP COLUMN IF EXISTS security_summary;
+ALTER TABLE charts DROP COLUMN IF EXISTS security_summary;
+
+-- +goose StatementEnd
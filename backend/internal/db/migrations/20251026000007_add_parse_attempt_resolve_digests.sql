@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Whether the import asked for image digests, so a retry repeats the same request
+ALTER TABLE chart_parse_attempts ADD COLUMN resolve_digests BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE chart_parse_attempts DROP COLUMN IF EXISTS resolve_digests;
+
+-- +goose StatementEnd
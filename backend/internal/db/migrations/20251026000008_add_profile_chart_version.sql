@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- The chart version the profile's environment deploys, NULL for the latest
+ALTER TABLE values_profiles ADD COLUMN chart_version TEXT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE values_profiles DROP COLUMN IF EXISTS chart_version;
+
+-- +goose StatementEnd
@@ -18,6 +18,7 @@ type App struct {
 	Configs   pgtype.Text      `json:"configs"`
 	Mounts    pgtype.Text      `json:"mounts"`
 	CreatedAt pgtype.Timestamp `json:"created_at"`
+	ProfileID pgtype.Int4      `json:"profile_id"`
 }
 
 type Chart struct {
@@ -37,6 +38,72 @@ type Chart struct {
 	ContainerImages  pgtype.Text      `json:"container_images"`
 	ServicePorts     pgtype.Text      `json:"service_ports"`
 	ManifestParsedAt pgtype.Timestamp `json:"manifest_parsed_at"`
+	KubeVersion      pgtype.Text      `json:"kube_version"`
+	ApiVersions      pgtype.Text      `json:"api_versions"`
+	Vendored         bool             `json:"vendored"`
+	SecuritySummary  pgtype.Text      `json:"security_summary"`
+}
+
+type ChartParseAttempt struct {
+	ID               int32            `json:"id"`
+	ChartUrl         string           `json:"chart_url"`
+	ChartName        pgtype.Text      `json:"chart_name"`
+	ChartVersion     pgtype.Text      `json:"chart_version"`
+	ValuesPath       pgtype.Text      `json:"values_path"`
+	SetValues        pgtype.Text      `json:"set_values"`
+	UseHostNetwork   bool             `json:"use_host_network"`
+	KubeVersion      pgtype.Text      `json:"kube_version"`
+	ApiVersions      pgtype.Text      `json:"api_versions"`
+	Status           string           `json:"status"`
+	Stage            string           `json:"stage"`
+	Category         string           `json:"category"`
+	Message          string           `json:"message"`
+	TemplateFile     pgtype.Text      `json:"template_file"`
+	TemplateLine     pgtype.Int4      `json:"template_line"`
+	Stack            pgtype.Text      `json:"stack"`
+	Retries          int32            `json:"retries"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+	UpdatedAt        pgtype.Timestamp `json:"updated_at"`
+	RequestedVersion pgtype.Text      `json:"requested_version"`
+	ResolveDigests   bool             `json:"resolve_digests"`
+}
+
+type ChartPin struct {
+	ChartName string           `json:"chart_name"`
+	Version   string           `json:"version"`
+	PinnedAt  pgtype.Timestamp `json:"pinned_at"`
+}
+
+type ChartRender struct {
+	ID                   int32            `json:"id"`
+	ChartID              int32            `json:"chart_id"`
+	ProfileID            int32            `json:"profile_id"`
+	Manifest             pgtype.Text      `json:"manifest"`
+	ImageTag             pgtype.Text      `json:"image_tag"`
+	CanaryTag            pgtype.Text      `json:"canary_tag"`
+	ContainerImages      pgtype.Text      `json:"container_images"`
+	IngressPaths         pgtype.Text      `json:"ingress_paths"`
+	ServicePorts         pgtype.Text      `json:"service_ports"`
+	DisabledDependencies pgtype.Text      `json:"disabled_dependencies"`
+	RenderedAt           pgtype.Timestamp `json:"rendered_at"`
+	KubeVersion          pgtype.Text      `json:"kube_version"`
+	ApiVersions          pgtype.Text      `json:"api_versions"`
+	SecuritySummary      pgtype.Text      `json:"security_summary"`
+}
+
+type ChartSetting struct {
+	ChartName          string           `json:"chart_name"`
+	IncludePrereleases bool             `json:"include_prereleases"`
+	UpdatedAt          pgtype.Timestamp `json:"updated_at"`
+}
+
+type ChartValue struct {
+	ChartID       int32            `json:"chart_id"`
+	DefaultValues pgtype.Text      `json:"default_values"`
+	ValuesSchema  pgtype.Text      `json:"values_schema"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
+	ValuesYaml    pgtype.Text      `json:"values_yaml"`
 }
 
 type Dependency struct {
@@ -49,6 +116,80 @@ type Dependency struct {
 	ImageTag          pgtype.Text      `json:"image_tag"`
 	CanaryTag         pgtype.Text      `json:"canary_tag"`
 	CreatedAt         pgtype.Timestamp `json:"created_at"`
+	Enabled           bool             `json:"enabled"`
+	Tags              pgtype.Text      `json:"tags"`
+	ResolvedVersion   pgtype.Text      `json:"resolved_version"`
+	Alias             string           `json:"alias"`
+	SecuritySummary   pgtype.Text      `json:"security_summary"`
+}
+
+type Image struct {
+	ID            int32            `json:"id"`
+	ChartID       int32            `json:"chart_id"`
+	ProfileID     pgtype.Int4      `json:"profile_id"`
+	Image         string           `json:"image"`
+	Registry      string           `json:"registry"`
+	Repository    string           `json:"repository"`
+	Tag           pgtype.Text      `json:"tag"`
+	Digest        pgtype.Text      `json:"digest"`
+	ChartVersion  string           `json:"chart_version"`
+	Workload      pgtype.Text      `json:"workload"`
+	WorkloadKind  pgtype.Text      `json:"workload_kind"`
+	ContainerName pgtype.Text      `json:"container_name"`
+	InitContainer bool             `json:"init_container"`
+	Subchart      pgtype.Text      `json:"subchart"`
+	Enabled       bool             `json:"enabled"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+}
+
+type ImageDigest struct {
+	ID             int32            `json:"id"`
+	Registry       string           `json:"registry"`
+	Repository     string           `json:"repository"`
+	Tag            string           `json:"tag"`
+	Digest         string           `json:"digest"`
+	MediaType      pgtype.Text      `json:"media_type"`
+	Platforms      pgtype.Text      `json:"platforms"`
+	SizeBytes      pgtype.Int8      `json:"size_bytes"`
+	ImageCreatedAt pgtype.Timestamp `json:"image_created_at"`
+	PreviousDigest pgtype.Text      `json:"previous_digest"`
+	DigestChanged  bool             `json:"digest_changed"`
+	ResolvedAt     pgtype.Timestamp `json:"resolved_at"`
+}
+
+type ImageScan struct {
+	ID         int32            `json:"id"`
+	Image      string           `json:"image"`
+	Registry   pgtype.Text      `json:"registry"`
+	Repository pgtype.Text      `json:"repository"`
+	Tag        pgtype.Text      `json:"tag"`
+	Digest     pgtype.Text      `json:"digest"`
+	Scanner    string           `json:"scanner"`
+	ScannedAt  pgtype.Timestamp `json:"scanned_at"`
+}
+
+type Policy struct {
+	ID          int32            `json:"id"`
+	Name        string           `json:"name"`
+	Description pgtype.Text      `json:"description"`
+	Action      string           `json:"action"`
+	Enabled     bool             `json:"enabled"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+	Expression  string           `json:"expression"`
+	Scope       string           `json:"scope"`
+	Message     pgtype.Text      `json:"message"`
+}
+
+type PolicyViolation struct {
+	ID         int32            `json:"id"`
+	ChartID    int32            `json:"chart_id"`
+	PolicyID   int32            `json:"policy_id"`
+	PolicyName string           `json:"policy_name"`
+	Action     string           `json:"action"`
+	Resource   pgtype.Text      `json:"resource"`
+	Message    string           `json:"message"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
 }
 
 type RegistryConfig struct {
@@ -61,3 +202,28 @@ type RegistryConfig struct {
 	CreatedAt   pgtype.Timestamp `json:"created_at"`
 	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
 }
+
+type ValuesProfile struct {
+	ID             int32            `json:"id"`
+	ChartName      string           `json:"chart_name"`
+	Name           string           `json:"name"`
+	ValuesFiles    pgtype.Text      `json:"values_files"`
+	SetValues      pgtype.Text      `json:"set_values"`
+	UseHostNetwork pgtype.Bool      `json:"use_host_network"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+	KubeVersion    pgtype.Text      `json:"kube_version"`
+	ApiVersions    pgtype.Text      `json:"api_versions"`
+	ChartVersion   pgtype.Text      `json:"chart_version"`
+}
+
+type Vulnerability struct {
+	ID               int32       `json:"id"`
+	ScanID           int32       `json:"scan_id"`
+	VulnerabilityID  string      `json:"vulnerability_id"`
+	PackageName      string      `json:"package_name"`
+	InstalledVersion pgtype.Text `json:"installed_version"`
+	FixedVersion     pgtype.Text `json:"fixed_version"`
+	Severity         string      `json:"severity"`
+	Title            pgtype.Text `json:"title"`
+}
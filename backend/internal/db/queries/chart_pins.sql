@@ -0,0 +1,13 @@
+-- name: GetChartPin :one
+SELECT * FROM chart_pins WHERE chart_name = $1;
+
+-- name: UpsertChartPin :one
+INSERT INTO chart_pins (chart_name, version)
+VALUES ($1, $2)
+ON CONFLICT (chart_name) DO UPDATE
+SET version = EXCLUDED.version,
+    pinned_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteChartPin :exec
+DELETE FROM chart_pins WHERE chart_name = $1;
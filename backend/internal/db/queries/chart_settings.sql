@@ -0,0 +1,13 @@
+-- name: GetChartSettings :one
+SELECT * FROM chart_settings WHERE chart_name = $1;
+
+-- name: UpsertChartSettings :one
+INSERT INTO chart_settings (chart_name, include_prereleases)
+VALUES ($1, $2)
+ON CONFLICT (chart_name) DO UPDATE
+SET include_prereleases = EXCLUDED.include_prereleases,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteChartSettings :exec
+DELETE FROM chart_settings WHERE chart_name = $1;
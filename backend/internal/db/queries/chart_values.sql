@@ -0,0 +1,14 @@
+-- name: GetChartValues :one
+SELECT * FROM chart_values WHERE chart_id = $1;
+
+-- name: UpsertChartValues :exec
+INSERT INTO chart_values (
+    chart_id, default_values, values_schema, values_yaml
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (chart_id) DO UPDATE
+SET default_values = EXCLUDED.default_values,
+    values_schema = EXCLUDED.values_schema,
+    values_yaml = EXCLUDED.values_yaml,
+    updated_at = CURRENT_TIMESTAMP;
@@ -13,18 +13,23 @@ SELECT * FROM charts WHERE is_latest = TRUE ORDER BY updated_at DESC;
 -- name: ListChartVersions :many
 SELECT * FROM charts WHERE name = $1 ORDER BY created_at DESC;
 
+-- name: ListAllChartVersions :many
+SELECT * FROM charts ORDER BY name ASC, created_at DESC;
+
 -- name: CreateChart :one
 INSERT INTO charts (
-    name, version, description, type, chart_url, image_tag, canary_tag, manifest, is_latest
+    name, version, description, type, chart_url, image_tag, canary_tag, manifest, is_latest, kube_version, api_versions,
+    vendored, security_summary
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 ) RETURNING *;
 
 -- name: UpdateChart :one
 UPDATE charts 
 SET version = $1, description = $2, type = $3, chart_url = $4, 
-    image_tag = $5, canary_tag = $6, manifest = $7, updated_at = CURRENT_TIMESTAMP
-WHERE name = $8 AND version = $9
+    image_tag = $5, canary_tag = $6, manifest = $7, kube_version = $8,
+    api_versions = $9, vendored = $12, security_summary = $13, updated_at = CURRENT_TIMESTAMP
+WHERE name = $10 AND version = $11
 RETURNING *;
 
 -- name: SetLatestVersion :exec
@@ -46,26 +51,27 @@ WHERE d.chart_id = $1;
 
 -- name: CreateDependency :one
 INSERT INTO dependencies (
-    chart_id, dependency_name, dependency_version, repository, condition_field
+    chart_id, dependency_name, dependency_version, repository, condition_field, image_tag, canary_tag, enabled, tags,
+    resolved_version, alias, security_summary
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 ) RETURNING *;
 
 -- name: DeleteChartDependencies :exec
 DELETE FROM dependencies WHERE chart_id = $1;
 
 -- name: GetChartApps :many
-SELECT * FROM apps WHERE chart_id = $1;
+SELECT * FROM apps WHERE chart_id = $1 AND profile_id IS NULL;
 
 -- name: CreateApp :one
 INSERT INTO apps (
-    chart_id, name, image, app_type, ports, configs, mounts
+    chart_id, name, image, app_type, ports, configs, mounts, profile_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
+    $1, $2, $3, $4, $5, $6, $7, $8
 ) RETURNING *;
 
 -- name: DeleteChartApps :exec
-DELETE FROM apps WHERE chart_id = $1;
+DELETE FROM apps WHERE chart_id = $1 AND profile_id IS NULL;
 
 -- name: SearchCharts :many
 SELECT * FROM charts 
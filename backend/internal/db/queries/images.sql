@@ -0,0 +1,62 @@
+-- name: CreateImage :exec
+INSERT INTO images (
+    chart_id, profile_id, image, registry, repository, tag, digest, chart_version,
+    workload, workload_kind, container_name, init_container, subchart, enabled
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+);
+
+-- name: DeleteChartImages :exec
+DELETE FROM images WHERE chart_id = $1 AND profile_id IS NULL;
+
+-- name: DeleteChartProfileImages :exec
+DELETE FROM images WHERE chart_id = $1 AND profile_id = $2;
+
+-- name: GetChartImages :many
+SELECT i.*, d.digest AS resolved_digest FROM images i
+LEFT JOIN image_digests d ON d.registry = i.registry AND d.repository = i.repository AND d.tag = i.tag
+WHERE i.chart_id = $1 AND i.profile_id IS NULL
+ORDER BY i.subchart NULLS FIRST, i.repository, i.tag;
+
+-- name: GetImageDigest :one
+SELECT * FROM image_digests
+WHERE registry = $1 AND repository = $2 AND tag = $3 LIMIT 1;
+
+-- name: ListImages :many
+SELECT i.*, c.name AS chart_name, vp.name AS profile_name,
+       d.digest AS resolved_digest, d.digest_changed AS tag_moved FROM images i
+JOIN charts c ON i.chart_id = c.id
+LEFT JOIN values_profiles vp ON i.profile_id = vp.id
+LEFT JOIN image_digests d ON d.registry = i.registry AND d.repository = i.repository AND d.tag = i.tag
+WHERE (sqlc.narg('repository')::text IS NULL OR i.repository ILIKE '%' || sqlc.narg('repository')::text || '%')
+  AND (sqlc.narg('tag')::text IS NULL OR i.tag = sqlc.narg('tag')::text)
+  AND (sqlc.narg('chart_name')::text IS NULL OR c.name = sqlc.narg('chart_name')::text)
+  AND ((sqlc.narg('profile')::text IS NULL AND i.profile_id IS NULL) OR vp.name = sqlc.narg('profile')::text)
+  AND (sqlc.arg('include_disabled')::boolean OR i.enabled)
+  AND (sqlc.arg('all_versions')::boolean OR c.is_latest = TRUE)
+ORDER BY c.name, i.repository, i.tag;
+
+-- name: ListDistinctImageTags :many
+SELECT DISTINCT i.registry, i.repository, i.tag FROM images i
+JOIN charts c ON i.chart_id = c.id
+WHERE i.tag IS NOT NULL
+  AND (sqlc.narg('chart_name')::text IS NULL OR c.name = sqlc.narg('chart_name')::text)
+ORDER BY i.registry, i.repository, i.tag;
+
+-- name: UpsertImageDigest :one
+INSERT INTO image_digests (
+    registry, repository, tag, digest, media_type, platforms, size_bytes, image_created_at,
+    previous_digest, digest_changed
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+)
+ON CONFLICT (registry, repository, tag) DO UPDATE
+SET previous_digest = EXCLUDED.previous_digest,
+    digest_changed = EXCLUDED.digest_changed,
+    digest = EXCLUDED.digest,
+    media_type = EXCLUDED.media_type,
+    platforms = EXCLUDED.platforms,
+    size_bytes = EXCLUDED.size_bytes,
+    image_created_at = EXCLUDED.image_created_at,
+    resolved_at = CURRENT_TIMESTAMP
+RETURNING *;
@@ -0,0 +1,45 @@
+-- name: CreateParseAttempt :one
+INSERT INTO chart_parse_attempts (
+    chart_url, chart_name, chart_version, values_path, set_values, use_host_network,
+    kube_version, api_versions, status, stage, category, message,
+    template_file, template_line, stack, requested_version, resolve_digests
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
+)
+RETURNING *;
+
+-- name: GetParseAttempt :one
+SELECT * FROM chart_parse_attempts WHERE id = $1;
+
+-- name: ListParseAttempts :many
+SELECT * FROM chart_parse_attempts
+WHERE (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status')::text)
+  AND (sqlc.narg('category')::text IS NULL OR category = sqlc.narg('category')::text)
+  AND (sqlc.narg('chart_name')::text IS NULL OR chart_name = sqlc.narg('chart_name')::text)
+ORDER BY updated_at DESC;
+
+-- name: UpdateParseAttempt :one
+UPDATE chart_parse_attempts
+SET chart_name = $2,
+    chart_version = $3,
+    status = $4,
+    stage = $5,
+    category = $6,
+    message = $7,
+    template_file = $8,
+    template_line = $9,
+    stack = $10,
+    retries = retries + 1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: ResolveParseAttempt :exec
+UPDATE chart_parse_attempts
+SET status = 'resolved',
+    retries = retries + 1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: DeleteParseAttempt :exec
+DELETE FROM chart_parse_attempts WHERE id = $1;
@@ -0,0 +1,37 @@
+-- name: ListPolicies :many
+SELECT * FROM policies ORDER BY name ASC;
+
+-- name: ListEnabledPolicies :many
+SELECT * FROM policies WHERE enabled = TRUE ORDER BY name ASC;
+
+-- name: UpsertPolicy :one
+INSERT INTO policies (
+    name, description, expression, scope, message, action, enabled
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (name) DO UPDATE
+SET description = EXCLUDED.description,
+    expression = EXCLUDED.expression,
+    scope = EXCLUDED.scope,
+    message = EXCLUDED.message,
+    action = EXCLUDED.action,
+    enabled = EXCLUDED.enabled,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeletePolicy :exec
+DELETE FROM policies WHERE name = $1;
+
+-- name: CreatePolicyViolation :exec
+INSERT INTO policy_violations (
+    chart_id, policy_id, policy_name, action, resource, message
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+);
+
+-- name: DeleteChartViolations :exec
+DELETE FROM policy_violations WHERE chart_id = $1;
+
+-- name: GetChartViolations :many
+SELECT * FROM policy_violations WHERE chart_id = $1 ORDER BY policy_name, resource;
@@ -0,0 +1,63 @@
+-- name: GetValuesProfile :one
+SELECT * FROM values_profiles WHERE chart_name = $1 AND name = $2 LIMIT 1;
+
+-- name: ListValuesProfiles :many
+SELECT * FROM values_profiles WHERE chart_name = $1 ORDER BY name ASC;
+
+-- name: UpsertValuesProfile :one
+INSERT INTO values_profiles (
+    chart_name, name, values_files, set_values, use_host_network, kube_version, api_versions,
+    chart_version
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (chart_name, name) DO UPDATE
+SET values_files = EXCLUDED.values_files,
+    set_values = EXCLUDED.set_values,
+    use_host_network = EXCLUDED.use_host_network,
+    kube_version = EXCLUDED.kube_version,
+    api_versions = EXCLUDED.api_versions,
+    chart_version = EXCLUDED.chart_version,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteValuesProfile :exec
+DELETE FROM values_profiles WHERE chart_name = $1 AND name = $2;
+
+-- name: GetChartRender :one
+SELECT * FROM chart_renders WHERE chart_id = $1 AND profile_id = $2 LIMIT 1;
+
+-- name: UpsertChartRender :one
+INSERT INTO chart_renders (
+    chart_id, profile_id, manifest, image_tag, canary_tag, container_images, ingress_paths, service_ports, disabled_dependencies, kube_version, api_versions,
+    security_summary
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+)
+ON CONFLICT (chart_id, profile_id) DO UPDATE
+SET manifest = EXCLUDED.manifest,
+    image_tag = EXCLUDED.image_tag,
+    canary_tag = EXCLUDED.canary_tag,
+    container_images = EXCLUDED.container_images,
+    ingress_paths = EXCLUDED.ingress_paths,
+    service_ports = EXCLUDED.service_ports,
+    disabled_dependencies = EXCLUDED.disabled_dependencies,
+    kube_version = EXCLUDED.kube_version,
+    api_versions = EXCLUDED.api_versions,
+    security_summary = EXCLUDED.security_summary,
+    rendered_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: GetChartProfileApps :many
+SELECT * FROM apps WHERE chart_id = $1 AND profile_id = $2;
+
+-- name: DeleteChartProfileApps :exec
+DELETE FROM apps WHERE chart_id = $1 AND profile_id = $2;
+
+-- name: GetDeployedProfileRender :one
+SELECT cr.*, c.version AS chart_version FROM chart_renders cr
+JOIN charts c ON cr.chart_id = c.id
+JOIN values_profiles vp ON cr.profile_id = vp.id
+WHERE c.name = $1 AND cr.profile_id = $2
+  AND (c.version = vp.chart_version OR (vp.chart_version IS NULL AND c.is_latest = true))
+LIMIT 1;
@@ -1,2 +1,6 @@
 -- name: GetDefaultRegistryConfig :one
 SELECT * FROM registry_configs WHERE is_default = TRUE LIMIT 1;
+
+
+-- name: ListRegistryConfigs :many
+SELECT * FROM registry_configs ORDER BY is_default DESC, name ASC;
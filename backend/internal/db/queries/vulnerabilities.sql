@@ -0,0 +1,36 @@
+-- name: UpsertImageScan :one
+INSERT INTO image_scans (
+    image, registry, repository, tag, digest, scanner
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (image, scanner) DO UPDATE
+SET registry = EXCLUDED.registry,
+    repository = EXCLUDED.repository,
+    tag = EXCLUDED.tag,
+    digest = EXCLUDED.digest,
+    scanned_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteScanVulnerabilities :exec
+DELETE FROM vulnerabilities WHERE scan_id = $1;
+
+-- name: CreateVulnerability :exec
+INSERT INTO vulnerabilities (
+    scan_id, vulnerability_id, package_name, installed_version, fixed_version, severity, title
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+);
+
+-- name: ListChartVulnerabilities :many
+SELECT DISTINCT i.chart_id, c.version AS chart_version, i.subchart, i.image, v.vulnerability_id, v.package_name, v.severity
+FROM images i
+JOIN charts c ON i.chart_id = c.id
+LEFT JOIN image_digests d ON d.registry = i.registry AND d.repository = i.repository AND d.tag = i.tag
+JOIN image_scans s ON (s.registry = i.registry AND s.repository = i.repository AND s.tag = i.tag)
+    OR s.digest = i.digest
+    OR s.digest = d.digest
+JOIN vulnerabilities v ON v.scan_id = s.id
+WHERE i.profile_id IS NULL
+  AND (i.enabled = TRUE OR sqlc.arg('include_disabled')::bool)
+  AND (sqlc.narg('chart_name')::text IS NULL OR c.name = sqlc.narg('chart_name')::text);
@@ -28,3 +28,36 @@ func (q *Queries) GetDefaultRegistryConfig(ctx context.Context) (RegistryConfig,
 	)
 	return i, err
 }
+
+const listRegistryConfigs = `-- name: ListRegistryConfigs :many
+SELECT id, name, registry_url, username, password, is_default, created_at, updated_at FROM registry_configs ORDER BY is_default DESC, name ASC
+`
+
+func (q *Queries) ListRegistryConfigs(ctx context.Context) ([]RegistryConfig, error) {
+	rows, err := q.db.Query(ctx, listRegistryConfigs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RegistryConfig
+	for rows.Next() {
+		var i RegistryConfig
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.RegistryUrl,
+			&i.Username,
+			&i.Password,
+			&i.IsDefault,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,208 @@
+package pkg
+
+import (
+	"chartpaper/internal/db"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/ashupednekar/compose/pkg/spec"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// AppFromDB decodes the JSON columns of a stored app.
+func AppFromDB(app db.App) App {
+	a := App{
+		Name:    app.Name,
+		Image:   app.Image.String,
+		Type:    app.AppType.String,
+		Ports:   []string{},
+		Configs: map[string]string{},
+		Mounts:  map[string]string{},
+	}
+	if app.Ports.Valid {
+		json.Unmarshal([]byte(app.Ports.String), &a.Ports)
+	}
+	if app.Configs.Valid {
+		json.Unmarshal([]byte(app.Configs.String), &a.Configs)
+	}
+	if app.Mounts.Valid {
+		json.Unmarshal([]byte(app.Mounts.String), &a.Mounts)
+	}
+	return a
+}
+
+// LoadChartApps returns the apps parsed for a chart version, from its default
+// render or from the render of the given profile.
+func LoadChartApps(ctx context.Context, queries *db.Queries, chart db.Chart, profileID pgtype.Int4) ([]App, error) {
+	var rows []db.App
+	var err error
+	if profileID.Valid {
+		rows, err = queries.GetChartProfileApps(ctx, db.GetChartProfileAppsParams{ChartID: chart.ID, ProfileID: profileID})
+	} else {
+		rows, err = queries.GetChartApps(ctx, chart.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load apps of %s v%s: %v", chart.Name, chart.Version, err)
+	}
+
+	apps := make([]App, 0, len(rows))
+	for _, row := range rows {
+		apps = append(apps, AppFromDB(row))
+	}
+	return apps, nil
+}
+
+// AppsFromManifest parses the apps of a rendered manifest, for renders compose
+// doesn't template itself: one app per container of every workload, with the
+// env values and mounted ConfigMap and Secret files it is rendered with as
+// configs, and its hostPath, PVC and emptyDir volumes as mounts.
+func AppsFromManifest(manifest string) []spec.App {
+	resources := decodeManifest(manifest)
+	files := map[string]map[string]string{}
+	for _, r := range resources {
+		if r.Kind() == "ConfigMap" || r.Kind() == "Secret" {
+			files[r.Kind()+"/"+r.Name()] = configData(r)
+		}
+	}
+
+	apps := []spec.App{}
+	for _, r := range resources {
+		podSpec := podSpec(r)
+		if podSpec == nil {
+			continue
+		}
+		volumes := map[string]map[string]interface{}{}
+		for _, volume := range nestedSlice(podSpec, "volumes") {
+			if v, ok := volume.(map[string]interface{}); ok {
+				volumes[nestedString(v, "name")] = v
+			}
+		}
+		if r.Kind() == "StatefulSet" {
+			for _, template := range nestedSlice(r.Object, "spec", "volumeClaimTemplates") {
+				t, _ := template.(map[string]interface{})
+				name := nestedString(t, "metadata", "name")
+				volumes[name] = map[string]interface{}{"name": name, "persistentVolumeClaim": map[string]interface{}{"claimName": name}}
+			}
+		}
+
+		containers, initCount := podContainers(podSpec)
+		containers = containers[initCount:]
+		for _, container := range containers {
+			name := r.Name()
+			if len(containers) > 1 {
+				name += "-" + nestedString(container, "name")
+			}
+			apps = append(apps, containerApp(name, strings.ToLower(r.Kind()), container, volumes, files))
+		}
+	}
+	return apps
+}
+
+// configData returns the keys of a ConfigMap or Secret, decoding Secret data.
+func configData(r manifestResource) map[string]string {
+	data := map[string]string{}
+	for key, value := range nestedMap(r.Object, "data") {
+		s := fmt.Sprint(value)
+		if r.Kind() == "Secret" {
+			if decoded, err := base64.StdEncoding.DecodeString(s); err == nil {
+				s = string(decoded)
+			}
+		}
+		data[key] = s
+	}
+	for key, value := range nestedMap(r.Object, "stringData") {
+		data[key] = fmt.Sprint(value)
+	}
+	return data
+}
+
+func containerApp(name, kind string, container map[string]interface{}, volumes map[string]map[string]interface{}, files map[string]map[string]string) spec.App {
+	app := spec.App{
+		Name:    name,
+		Image:   nestedString(container, "image"),
+		Type:    kind,
+		Ports:   []string{},
+		Configs: map[string]string{},
+		Mounts:  map[string]string{},
+	}
+
+	for _, port := range nestedSlice(container, "ports") {
+		p, _ := port.(map[string]interface{})
+		containerPort := nestedValue(p, "containerPort")
+		if containerPort == nil {
+			continue
+		}
+		mapping := fmt.Sprintf("%v:%v", containerPort, containerPort)
+		if hostPort := nestedValue(p, "hostPort"); hostPort != nil {
+			mapping = fmt.Sprintf("%v:%v", hostPort, containerPort)
+		}
+		if protocol := nestedString(p, "protocol"); protocol != "" && protocol != "TCP" {
+			mapping += "/" + strings.ToLower(protocol)
+		}
+		app.Ports = append(app.Ports, mapping)
+	}
+
+	for _, envFrom := range nestedSlice(container, "envFrom") {
+		e, _ := envFrom.(map[string]interface{})
+		prefix := nestedString(e, "prefix")
+		for key, value := range files["ConfigMap/"+nestedString(e, "configMapRef", "name")] {
+			app.Configs[prefix+key] = value
+		}
+		for key, value := range files["Secret/"+nestedString(e, "secretRef", "name")] {
+			app.Configs[prefix+key] = value
+		}
+	}
+	for _, env := range nestedSlice(container, "env") {
+		e, _ := env.(map[string]interface{})
+		name := nestedString(e, "name")
+		switch {
+		case nestedMap(e, "valueFrom", "configMapKeyRef") != nil:
+			app.Configs[name] = files["ConfigMap/"+nestedString(e, "valueFrom", "configMapKeyRef", "name")][nestedString(e, "valueFrom", "configMapKeyRef", "key")]
+		case nestedMap(e, "valueFrom", "secretKeyRef") != nil:
+			app.Configs[name] = files["Secret/"+nestedString(e, "valueFrom", "secretKeyRef", "name")][nestedString(e, "valueFrom", "secretKeyRef", "key")]
+		case nestedValue(e, "valueFrom") == nil:
+			app.Configs[name] = nestedString(e, "value")
+		}
+	}
+
+	for _, mount := range nestedSlice(container, "volumeMounts") {
+		m, _ := mount.(map[string]interface{})
+		target := nestedString(m, "mountPath")
+		subPath := nestedString(m, "subPath")
+		volume := volumes[nestedString(m, "name")]
+		if volume == nil || target == "" {
+			continue
+		}
+
+		var content map[string]string
+		switch {
+		case nestedMap(volume, "configMap") != nil:
+			content = files["ConfigMap/"+nestedString(volume, "configMap", "name")]
+		case nestedMap(volume, "secret") != nil:
+			content = files["Secret/"+nestedString(volume, "secret", "secretName")]
+		case nestedMap(volume, "hostPath") != nil:
+			app.Mounts[path.Join(nestedString(volume, "hostPath", "path"), subPath)] = target
+			continue
+		case nestedMap(volume, "persistentVolumeClaim") != nil:
+			app.Mounts[nestedString(volume, "persistentVolumeClaim", "claimName")] = target
+			continue
+		default:
+			app.Mounts[nestedString(volume, "name")] = target
+			continue
+		}
+
+		// A subPath mounts a single key as the target file
+		if subPath != "" {
+			app.Configs[target] = content[subPath]
+			continue
+		}
+		for key, value := range content {
+			app.Configs[path.Join(target, key)] = value
+		}
+	}
+	return app
+}
@@ -0,0 +1,51 @@
+package pkg
+
+import (
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// renderChart renders a loaded chart client-side like `helm template`. An
+// empty kubeVersion keeps Helm's default capabilities.
+func renderChart(ch *chart.Chart, vals map[string]interface{}, releaseName, namespace, kubeVersion string, apiVersions []string) (*release.Release, error) {
+	client := action.NewInstall(&action.Configuration{Log: func(string, ...interface{}) {}})
+	client.DryRun = true
+	client.ClientOnly = true
+	client.Replace = true
+	client.IncludeCRDs = true
+	client.ReleaseName = releaseName
+	client.Namespace = namespace
+	if client.Namespace == "" {
+		client.Namespace = "default"
+	}
+	if kubeVersion != "" {
+		kv, err := chartutil.ParseKubeVersion(kubeVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kube version %q: %v", kubeVersion, err)
+		}
+		client.KubeVersion = kv
+	}
+	client.APIVersions = chartutil.VersionSet(apiVersions)
+
+	if vals == nil {
+		vals = map[string]interface{}{}
+	}
+	rendered, err := client.Run(ch, vals)
+	if err != nil {
+		return nil, err
+	}
+
+	// Like helm template, hooks are part of the rendered output
+	var manifest strings.Builder
+	manifest.WriteString(strings.TrimSpace(rendered.Manifest))
+	for _, hook := range rendered.Hooks {
+		fmt.Fprintf(&manifest, "\n---\n# Source: %s\n%s", hook.Path, strings.TrimSpace(hook.Manifest))
+	}
+	rendered.Manifest = manifest.String()
+	return rendered, nil
+}
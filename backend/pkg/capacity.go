@@ -0,0 +1,246 @@
+package pkg
+
+import (
+	"chartpaper/internal/db"
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func (t *ResourceTotals) add(other ResourceTotals, times int64) {
+	t.CPURequests += other.CPURequests * times
+	t.CPULimits += other.CPULimits * times
+	t.MemoryRequests += other.MemoryRequests * times
+	t.MemoryLimits += other.MemoryLimits * times
+}
+
+// max keeps the larger of each total, the way the scheduler accounts for init
+// containers, which run one at a time before the regular containers.
+func (t *ResourceTotals) max(other ResourceTotals) {
+	t.CPURequests = max(t.CPURequests, other.CPURequests)
+	t.CPULimits = max(t.CPULimits, other.CPULimits)
+	t.MemoryRequests = max(t.MemoryRequests, other.MemoryRequests)
+	t.MemoryLimits = max(t.MemoryLimits, other.MemoryLimits)
+}
+
+// quantity parses a Kubernetes quantity, returning false when it is missing
+// or can't be parsed (e.g. an unrendered template expression).
+func quantity(obj map[string]interface{}, fields ...string) (resource.Quantity, bool) {
+	value := nestedValue(obj, fields...)
+	if value == nil {
+		return resource.Quantity{}, false
+	}
+	q, err := resource.ParseQuantity(fmt.Sprint(value))
+	if err != nil {
+		return resource.Quantity{}, false
+	}
+	return q, true
+}
+
+func containerResources(container map[string]interface{}) (totals ResourceTotals, hasRequests, hasLimits bool) {
+	if q, ok := quantity(container, "resources", "requests", "cpu"); ok {
+		totals.CPURequests = q.MilliValue()
+		hasRequests = true
+	}
+	if q, ok := quantity(container, "resources", "requests", "memory"); ok {
+		totals.MemoryRequests = q.Value()
+		hasRequests = true
+	}
+	if q, ok := quantity(container, "resources", "limits", "cpu"); ok {
+		totals.CPULimits = q.MilliValue()
+		hasLimits = true
+	}
+	if q, ok := quantity(container, "resources", "limits", "memory"); ok {
+		totals.MemoryLimits = q.Value()
+		hasLimits = true
+	}
+	return totals, hasRequests, hasLimits
+}
+
+// workloadReplicas is the number of pods a workload runs. DaemonSets run one
+// per node, so they are counted once and reported in the warnings.
+func workloadReplicas(r manifestResource) int64 {
+	var value interface{}
+	switch r.Kind() {
+	case "Deployment", "StatefulSet", "ReplicaSet", "ReplicationController":
+		value = nestedValue(r.Object, "spec", "replicas")
+	case "Job":
+		value = nestedValue(r.Object, "spec", "parallelism")
+	case "CronJob":
+		value = nestedValue(r.Object, "spec", "jobTemplate", "spec", "parallelism")
+	}
+	switch v := value.(type) {
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	}
+	return 1
+}
+
+// CalculateCapacity totals the requests, limits, replicas, HPA bounds and
+// PVC storage of a rendered manifest.
+func CalculateCapacity(manifest string) ChartCapacity {
+	capacity := ChartCapacity{Workloads: []WorkloadCapacity{}}
+	resources := decodeManifest(manifest)
+
+	type hpaBounds struct{ min, max int64 }
+	hpas := map[string]hpaBounds{}
+	for _, r := range resources {
+		if r.Kind() != "HorizontalPodAutoscaler" {
+			continue
+		}
+		bounds := hpaBounds{min: 1}
+		if v, ok := nestedValue(r.Object, "spec", "minReplicas").(float64); ok {
+			bounds.min = int64(v)
+		}
+		if v, ok := nestedValue(r.Object, "spec", "maxReplicas").(float64); ok {
+			bounds.max = int64(v)
+		}
+		target := nestedString(r.Object, "spec", "scaleTargetRef", "kind") + "/" + nestedString(r.Object, "spec", "scaleTargetRef", "name")
+		hpas[target] = bounds
+	}
+
+	for _, r := range resources {
+		switch r.Kind() {
+		case "PersistentVolumeClaim":
+			capacity.Claims++
+			if q, ok := quantity(r.Object, "spec", "resources", "requests", "storage"); ok {
+				capacity.StorageBytes += q.Value()
+			}
+			continue
+		case "DaemonSet":
+			capacity.Warnings = append(capacity.Warnings, fmt.Sprintf("DaemonSet %s runs one pod per node and is counted once", r.Name()))
+		}
+
+		spec := podSpec(r)
+		if spec == nil {
+			continue
+		}
+		workload := WorkloadCapacity{
+			Kind:     r.Kind(),
+			Name:     r.Name(),
+			Subchart: subchartFromSource(r.Source),
+			Replicas: workloadReplicas(r),
+		}
+
+		containers, initCount := podContainers(spec)
+		var initTotals ResourceTotals
+		for i, container := range containers {
+			totals, hasRequests, hasLimits := containerResources(container)
+			name := nestedString(container, "name")
+			if !hasRequests {
+				workload.MissingRequests = append(workload.MissingRequests, name)
+			}
+			if !hasLimits {
+				workload.MissingLimits = append(workload.MissingLimits, name)
+			}
+			if i < initCount {
+				initTotals.max(totals)
+			} else {
+				workload.PerReplica.add(totals, 1)
+			}
+		}
+		workload.PerReplica.max(initTotals)
+
+		// An HPA never scales below its minimum, whatever replicas says
+		replicas, maxReplicas := workload.Replicas, workload.Replicas
+		if bounds, ok := hpas[r.Kind()+"/"+r.Name()]; ok {
+			workload.HPAMin = bounds.min
+			workload.HPAMax = bounds.max
+			replicas = max(replicas, bounds.min)
+			maxReplicas = max(maxReplicas, bounds.max)
+		}
+		workload.Total.add(workload.PerReplica, replicas)
+		workload.MaxTotal.add(workload.PerReplica, maxReplicas)
+
+		if r.Kind() == "StatefulSet" {
+			for _, template := range nestedSlice(r.Object, "spec", "volumeClaimTemplates") {
+				t, _ := template.(map[string]interface{})
+				capacity.Claims += int(replicas)
+				if q, ok := quantity(t, "spec", "resources", "requests", "storage"); ok {
+					capacity.StorageBytes += q.Value() * replicas
+				}
+			}
+		}
+
+		capacity.Replicas += replicas
+		capacity.MaxReplicas += maxReplicas
+		capacity.Total.add(workload.Total, 1)
+		capacity.MaxTotal.add(workload.MaxTotal, 1)
+		capacity.Workloads = append(capacity.Workloads, workload)
+	}
+	return capacity
+}
+
+// mergeCapacity adds the footprint of a separately stored dependency, its
+// workloads attributed to the dependency as a subchart.
+func (c *ChartCapacity) mergeCapacity(subchart string, other ChartCapacity) {
+	for _, workload := range other.Workloads {
+		workload.Subchart = strings.TrimSuffix(subchart+"/"+workload.Subchart, "/")
+		c.Workloads = append(c.Workloads, workload)
+	}
+	c.Replicas += other.Replicas
+	c.MaxReplicas += other.MaxReplicas
+	c.Total.add(other.Total, 1)
+	c.MaxTotal.add(other.MaxTotal, 1)
+	c.Claims += other.Claims
+	c.StorageBytes += other.StorageBytes
+	c.Warnings = append(c.Warnings, other.Warnings...)
+}
+
+// ChartVersionCapacity calculates the footprint of a chart version rendered
+// with manifest. Enabled dependencies that weren't rendered with the parent,
+// because they aren't vendored under charts/, are added from their own
+// stored version, and so on down their own dependencies.
+func ChartVersionCapacity(ctx context.Context, queries *db.Queries, chart db.Chart, profile, manifest string, disabled map[string]bool) (ChartCapacity, error) {
+	capacity := CalculateCapacity(manifest)
+	capacity.Chart = chart.Name
+	capacity.Version = chart.Version
+	capacity.Profile = profile
+
+	err := capacity.addDependencies(ctx, queries, chart, manifest, "", disabled, map[int32]bool{chart.ID: true})
+	return capacity, err
+}
+
+// addDependencies merges the stored versions of the enabled dependencies of
+// chart that manifest didn't render, attributed under prefix. visited holds
+// the versions on the current path, so a dependency cycle ends there while
+// the same chart pulled in under two aliases still counts twice.
+func (c *ChartCapacity) addDependencies(ctx context.Context, queries *db.Queries, chart db.Chart, manifest, prefix string, disabled map[string]bool, visited map[int32]bool) error {
+	rendered := RenderedSubcharts(manifest)
+
+	deps, err := queries.GetChartDependencies(ctx, chart.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load dependencies of %s: %v", chart.Name, err)
+	}
+	for _, dep := range deps {
+		name := StoredDependencyKey(dep)
+		if !dep.Enabled || disabled[name] || rendered[name] {
+			continue
+		}
+		version := ResolvedDependencyVersion(dep)
+		stored, err := queries.GetChartVersion(ctx, db.GetChartVersionParams{Name: dep.DependencyName, Version: version})
+		if err != nil {
+			c.Warnings = append(c.Warnings, fmt.Sprintf("Dependency %s %s is not stored and is not counted", dep.DependencyName, version))
+			continue
+		}
+		if visited[stored.ID] {
+			c.Warnings = append(c.Warnings, fmt.Sprintf("Dependency %s %s depends on itself and is counted once", dep.DependencyName, version))
+			continue
+		}
+
+		subchart := strings.TrimPrefix(prefix+"/"+name, "/")
+		c.mergeCapacity(subchart, CalculateCapacity(stored.Manifest.String))
+		c.Dependencies = append(c.Dependencies, subchart)
+		visited[stored.ID] = true
+		err = c.addDependencies(ctx, queries, stored, stored.Manifest.String, subchart, nil, visited)
+		delete(visited, stored.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
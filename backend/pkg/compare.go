@@ -0,0 +1,108 @@
+package pkg
+
+import (
+	"sort"
+	"strings"
+)
+
+// ProfileSnapshot is what a chart looked like when rendered with one profile.
+type ProfileSnapshot struct {
+	Version  string
+	Manifest string
+}
+
+type ContainerComparison struct {
+	Workload     string            `json:"workload"`
+	WorkloadKind string            `json:"workloadKind"`
+	Container    string            `json:"container"`
+	Images       map[string]string `json:"images"`
+	Tags         map[string]string `json:"tags"`
+	Drift        bool              `json:"drift"`
+}
+
+type ChartComparison struct {
+	Chart        string                `json:"chart"`
+	Versions     map[string]string     `json:"versions"`
+	VersionDrift bool                  `json:"versionDrift"`
+	Containers   []ContainerComparison `json:"containers"`
+	Drift        bool                  `json:"drift"`
+	Missing      []string              `json:"missing,omitempty"`
+}
+
+type Comparison struct {
+	Profiles   []string          `json:"profiles"`
+	Charts     []ChartComparison `json:"charts"`
+	DriftCount int               `json:"driftCount"`
+}
+
+// CompareChart builds the container by profile matrix of a chart from the
+// render of the version each profile deploys. A container drifts when the
+// profiles disagree on its image tag or when it only exists in some of them.
+func CompareChart(chart string, profiles []string, snapshots map[string]ProfileSnapshot) ChartComparison {
+	comparison := ChartComparison{
+		Chart:      chart,
+		Versions:   map[string]string{},
+		Containers: []ContainerComparison{},
+	}
+
+	rendered := []string{}
+	for _, profile := range profiles {
+		snapshot, ok := snapshots[profile]
+		if !ok {
+			comparison.Missing = append(comparison.Missing, profile)
+			continue
+		}
+		rendered = append(rendered, profile)
+		comparison.Versions[profile] = snapshot.Version
+	}
+	comparison.VersionDrift = distinctValues(comparison.Versions, rendered) > 1
+
+	byKey := map[string]*ContainerComparison{}
+	var keys []string
+	for _, profile := range rendered {
+		for _, image := range ExtractContainerImages(snapshots[profile].Manifest) {
+			key := strings.Join([]string{image.WorkloadKind, image.Workload, image.Container}, "/")
+			entry, ok := byKey[key]
+			if !ok {
+				entry = &ContainerComparison{
+					Workload:     image.Workload,
+					WorkloadKind: image.WorkloadKind,
+					Container:    image.Container,
+					Images:       map[string]string{},
+					Tags:         map[string]string{},
+				}
+				byKey[key] = entry
+				keys = append(keys, key)
+			}
+			entry.Images[profile] = image.Image
+			entry.Tags[profile] = image.Tag
+			if image.Digest != "" {
+				entry.Tags[profile] = strings.TrimPrefix(image.Tag+"@"+image.Digest, "@")
+			}
+		}
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		entry := byKey[key]
+		entry.Drift = len(entry.Tags) != len(rendered) || distinctValues(entry.Tags, rendered) > 1
+		if entry.Drift {
+			comparison.Drift = true
+		}
+		comparison.Containers = append(comparison.Containers, *entry)
+	}
+	if comparison.VersionDrift {
+		comparison.Drift = true
+	}
+	return comparison
+}
+
+func distinctValues(values map[string]string, keys []string) int {
+	seen := map[string]bool{}
+	for _, key := range keys {
+		if value, ok := values[key]; ok {
+			seen[value] = true
+		}
+	}
+	return len(seen)
+}
@@ -0,0 +1,253 @@
+package pkg
+
+import (
+	"chartpaper/internal/db"
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"gopkg.in/yaml.v3"
+)
+
+// ComposeFile is the subset of the compose specification chartpaper exports.
+type ComposeFile struct {
+	Services map[string]ComposeService    `yaml:"services"`
+	Configs  map[string]ComposeConfig     `yaml:"configs,omitempty"`
+	Volumes  map[string]map[string]string `yaml:"volumes,omitempty"`
+}
+
+type ComposeService struct {
+	Image       string                 `yaml:"image,omitempty"`
+	Restart     string                 `yaml:"restart,omitempty"`
+	NetworkMode string                 `yaml:"network_mode,omitempty"`
+	Ports       []string               `yaml:"ports,omitempty"`
+	Environment map[string]string      `yaml:"environment,omitempty"`
+	Configs     []ComposeServiceConfig `yaml:"configs,omitempty"`
+	Volumes     []string               `yaml:"volumes,omitempty"`
+	DependsOn   []string               `yaml:"depends_on,omitempty"`
+}
+
+// ComposeConfig is a top level config inlined with the content of a file the
+// chart renders, such as a ConfigMap key.
+type ComposeConfig struct {
+	Content string `yaml:"content"`
+}
+
+type ComposeServiceConfig struct {
+	Source string `yaml:"source"`
+	Target string `yaml:"target"`
+}
+
+var composeNamePattern = regexp.MustCompile(`[^a-z0-9_.-]+`)
+
+// composeName lowercases a name and replaces what compose doesn't accept in
+// service, config and volume names.
+func composeName(name string) string {
+	return strings.Trim(composeNamePattern.ReplaceAllString(strings.ToLower(name), "-"), "-.")
+}
+
+// composeApp is an app together with the services of the dependencies it
+// starts after.
+type composeApp struct {
+	App
+	dependsOn []string
+}
+
+// BuildComposeFile turns the stored apps of a chart version and of its enabled
+// dependencies into a compose file. The chart and the subcharts it renders are
+// taken from the profile's render when one is given; the chart's own apps
+// start after every dependency.
+func BuildComposeFile(ctx context.Context, queries *db.Queries, chart db.Chart, render *db.ChartRender, useHostNetwork bool) (*ComposeFile, error) {
+	var profileID pgtype.Int4
+	disabled := map[string]bool{}
+	if render != nil {
+		profileID = pgtype.Int4{Int32: render.ProfileID, Valid: true}
+		disabled = DisabledDependencies(*render)
+	}
+
+	apps, err := LoadChartApps(ctx, queries, chart, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Subcharts are taken from the same render as the chart, so a profile's
+	// values apply to them too
+	manifest := chart.Manifest.String
+	if render != nil {
+		manifest = render.Manifest.String
+	}
+	var dependencyApps []composeApp
+	if err := collectDependencyApps(ctx, queries, chart.ID, manifest, chart.Name, disabled, &dependencyApps, map[int32]bool{chart.ID: true}); err != nil {
+		return nil, err
+	}
+
+	var dependencyServices []string
+	for _, app := range dependencyApps {
+		dependencyServices = append(dependencyServices, composeName(app.Name))
+	}
+	all := dependencyApps
+	for _, app := range apps {
+		all = append(all, composeApp{App: app, dependsOn: dependencyServices})
+	}
+
+	file := &ComposeFile{
+		Services: map[string]ComposeService{},
+		Configs:  map[string]ComposeConfig{},
+		Volumes:  map[string]map[string]string{},
+	}
+	for _, app := range all {
+		name := composeName(app.Name)
+		if _, exists := file.Services[name]; exists {
+			// The chart's apps repeat the workloads of the subcharts it
+			// renders, and a subchart can be pulled in twice; keep the first
+			continue
+		}
+		file.Services[name] = composeService(name, app, file, useHostNetwork)
+	}
+	return file, nil
+}
+
+// collectDependencyApps appends the apps of every enabled dependency,
+// dependencies of dependencies first. A subchart rendered with the chart at
+// chartPath is read from that render; any other dependency from the stored
+// version it resolved to.
+func collectDependencyApps(ctx context.Context, queries *db.Queries, chartID int32, manifest, chartPath string, disabled map[string]bool, apps *[]composeApp, visited map[int32]bool) error {
+	deps, err := queries.GetChartDependencies(ctx, chartID)
+	if err != nil {
+		return fmt.Errorf("failed to load dependencies: %v", err)
+	}
+	for _, dep := range deps {
+		name := StoredDependencyKey(dep)
+		if !dep.Enabled || disabled[name] {
+			continue
+		}
+		if subchartPath := chartPath + "/charts/" + name; manifestForChartPath(manifest, subchartPath) != "" {
+			collectRenderedApps(manifest, subchartPath, apps)
+			continue
+		}
+
+		stored, err := queries.GetChartVersion(ctx, db.GetChartVersionParams{Name: dep.DependencyName, Version: ResolvedDependencyVersion(dep)})
+		if err != nil || visited[stored.ID] {
+			continue
+		}
+		visited[stored.ID] = true
+
+		before := len(*apps)
+		if err := collectDependencyApps(ctx, queries, stored.ID, stored.Manifest.String, stored.Name, nil, apps, visited); err != nil {
+			return err
+		}
+		var nested []string
+		for _, app := range (*apps)[before:] {
+			nested = append(nested, composeName(app.Name))
+		}
+
+		depApps, err := LoadChartApps(ctx, queries, stored, pgtype.Int4{})
+		if err != nil {
+			return err
+		}
+		for _, app := range depApps {
+			*apps = append(*apps, composeApp{App: app, dependsOn: nested})
+		}
+	}
+	return nil
+}
+
+// collectRenderedApps appends the apps rendered from the subchart at
+// chartPath, those of its own subcharts first.
+func collectRenderedApps(manifest, chartPath string, apps *[]composeApp) {
+	before := len(*apps)
+	for _, sub := range renderedChildCharts(manifest, chartPath) {
+		collectRenderedApps(manifest, chartPath+"/charts/"+sub, apps)
+	}
+	var nested []string
+	for _, app := range (*apps)[before:] {
+		nested = append(nested, composeName(app.Name))
+	}
+
+	for _, app := range AppsFromManifest(manifestForTemplates(manifest, chartPath)) {
+		*apps = append(*apps, composeApp{
+			App: App{
+				Name:    app.Name,
+				Image:   app.Image,
+				Type:    app.Type,
+				Ports:   app.Ports,
+				Configs: app.Configs,
+				Mounts:  app.Mounts,
+			},
+			dependsOn: nested,
+		})
+	}
+}
+
+// composeService converts one app. Configs keyed by an absolute path become
+// config files mounted at that path, any other key is an environment
+// variable. Mounts map a host path or named volume to a container path.
+func composeService(name string, app composeApp, file *ComposeFile, useHostNetwork bool) ComposeService {
+	service := ComposeService{
+		Image:     app.Image,
+		Restart:   "unless-stopped",
+		DependsOn: app.dependsOn,
+	}
+	if strings.EqualFold(app.Type, "job") || strings.EqualFold(app.Type, "cronjob") {
+		service.Restart = "no"
+	}
+
+	// Ports can't be published in host network mode, the app binds them directly
+	if useHostNetwork {
+		service.NetworkMode = "host"
+	} else {
+		service.Ports = app.Ports
+	}
+
+	keys := make([]string, 0, len(app.Configs))
+	for key := range app.Configs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !strings.HasPrefix(key, "/") {
+			if service.Environment == nil {
+				service.Environment = map[string]string{}
+			}
+			service.Environment[key] = app.Configs[key]
+			continue
+		}
+		source := composeName(name + "-" + path.Base(key))
+		for i := 2; ; i++ {
+			if _, taken := file.Configs[source]; !taken {
+				break
+			}
+			source = composeName(fmt.Sprintf("%s-%s-%d", name, path.Base(key), i))
+		}
+		file.Configs[source] = ComposeConfig{Content: app.Configs[key]}
+		service.Configs = append(service.Configs, ComposeServiceConfig{Source: source, Target: key})
+	}
+
+	sources := make([]string, 0, len(app.Mounts))
+	for source := range app.Mounts {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		target := app.Mounts[source]
+		if !strings.HasPrefix(source, "/") && !strings.HasPrefix(source, ".") {
+			source = composeName(source)
+			file.Volumes[source] = map[string]string{}
+		}
+		service.Volumes = append(service.Volumes, source+":"+target)
+	}
+	return service
+}
+
+// YAML encodes the compose file.
+func (f *ComposeFile) YAML() ([]byte, error) {
+	out, err := yaml.Marshal(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode compose file: %v", err)
+	}
+	return out, nil
+}
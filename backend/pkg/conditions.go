@@ -0,0 +1,66 @@
+package pkg
+
+import (
+	"log"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// computedValues coalesces the chart defaults with the values the release was
+// rendered with, which is what Helm evaluates conditions and tags against.
+func computedValues(ch *chart.Chart, config map[string]interface{}) chartutil.Values {
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+	vals, err := chartutil.CoalesceValues(ch, config)
+	if err != nil {
+		log.Printf("⚠️  Could not compute values for %s: %v\n", ch.Name(), err)
+		return chartutil.Values{}
+	}
+	return vals
+}
+
+// evaluateDependencyConditions marks every dependency enabled or disabled
+// following Helm's rules: tags are applied first, then the first condition
+// path that resolves to a boolean wins. path is the dotted values prefix of
+// the chart declaring the dependencies ("" for the root chart).
+func evaluateDependencyConditions(deps []Dependency, vals chartutil.Values, path string) {
+	tags, _ := vals.Table("tags")
+
+	for i := range deps {
+		dep := &deps[i]
+		dep.Enabled = true
+
+		var hasTrue, hasFalse bool
+		for _, tag := range dep.Tags {
+			if enabled, ok := tags[tag].(bool); ok {
+				if enabled {
+					hasTrue = true
+				} else {
+					hasFalse = true
+				}
+			}
+		}
+		if !hasTrue && hasFalse {
+			dep.Enabled = false
+		}
+
+		for _, condition := range strings.Split(strings.TrimSpace(dep.Condition), ",") {
+			condition = strings.TrimSpace(condition)
+			if condition == "" {
+				continue
+			}
+			value, err := vals.PathValue(path + condition)
+			if err != nil {
+				continue
+			}
+			if enabled, ok := value.(bool); ok {
+				dep.Enabled = enabled
+				break
+			}
+			log.Printf("⚠️  Condition path %s for %s returned non-bool value\n", condition, dep.Name)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package pkg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// APIDeprecation records when a Kubernetes API version of a kind was
+// deprecated and removed, and what replaces it.
+type APIDeprecation struct {
+	APIVersion   string `json:"apiVersion"`
+	Kind         string `json:"kind"`
+	DeprecatedIn string `json:"deprecatedIn"`
+	RemovedIn    string `json:"removedIn,omitempty"`
+	Replacement  string `json:"replacement,omitempty"`
+}
+
+// apiDeprecations follows the upstream Kubernetes deprecated API migration guide.
+var apiDeprecations = []APIDeprecation{
+	{"extensions/v1beta1", "Deployment", "1.9", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "DaemonSet", "1.9", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "ReplicaSet", "1.9", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "NetworkPolicy", "1.9", "1.16", "networking.k8s.io/v1"},
+	{"extensions/v1beta1", "PodSecurityPolicy", "1.11", "1.16", "policy/v1beta1"},
+	{"apps/v1beta1", "Deployment", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta1", "StatefulSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "Deployment", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "DaemonSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "ReplicaSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "StatefulSet", "1.9", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "Ingress", "1.14", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "Ingress", "1.19", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "IngressClass", "1.19", "1.22", "networking.k8s.io/v1"},
+	{"apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "1.16", "1.22", "apiextensions.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "MutatingWebhookConfiguration", "1.16", "1.22", "admissionregistration.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "ValidatingWebhookConfiguration", "1.16", "1.22", "admissionregistration.k8s.io/v1"},
+	{"apiregistration.k8s.io/v1beta1", "APIService", "1.19", "1.22", "apiregistration.k8s.io/v1"},
+	{"certificates.k8s.io/v1beta1", "CertificateSigningRequest", "1.19", "1.22", "certificates.k8s.io/v1"},
+	{"coordination.k8s.io/v1beta1", "Lease", "1.19", "1.22", "coordination.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRole", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRoleBinding", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "Role", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "RoleBinding", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"scheduling.k8s.io/v1beta1", "PriorityClass", "1.14", "1.22", "scheduling.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSIDriver", "1.19", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSINode", "1.17", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "StorageClass", "1.19", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "VolumeAttachment", "1.19", "1.22", "storage.k8s.io/v1"},
+	{"batch/v1beta1", "CronJob", "1.21", "1.25", "batch/v1"},
+	{"discovery.k8s.io/v1beta1", "EndpointSlice", "1.21", "1.25", "discovery.k8s.io/v1"},
+	{"events.k8s.io/v1beta1", "Event", "1.19", "1.25", "events.k8s.io/v1"},
+	{"autoscaling/v2beta1", "HorizontalPodAutoscaler", "1.22", "1.25", "autoscaling/v2"},
+	{"policy/v1beta1", "PodDisruptionBudget", "1.21", "1.25", "policy/v1"},
+	{"policy/v1beta1", "PodSecurityPolicy", "1.21", "1.25", ""},
+	{"node.k8s.io/v1beta1", "RuntimeClass", "1.20", "1.25", "node.k8s.io/v1"},
+	{"autoscaling/v2beta2", "HorizontalPodAutoscaler", "1.23", "1.26", "autoscaling/v2"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "FlowSchema", "1.23", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "PriorityLevelConfiguration", "1.23", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSIStorageCapacity", "1.24", "1.27", "storage.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "FlowSchema", "1.26", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "PriorityLevelConfiguration", "1.26", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "FlowSchema", "1.29", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "PriorityLevelConfiguration", "1.29", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+// DeprecationFinding is a rendered resource using an API the target cluster
+// version deprecates or no longer serves.
+type DeprecationFinding struct {
+	APIDeprecation
+	Name    string `json:"name"`
+	Source  string `json:"source,omitempty"`
+	Removed bool   `json:"removed"`
+}
+
+// ParseKubeVersion accepts versions like 1.29, v1.29 or 1.29.3.
+func ParseKubeVersion(version string) (*semver.Version, error) {
+	v, err := semver.NewVersion(strings.TrimPrefix(strings.TrimSpace(version), "v"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Kubernetes version %q: %v", version, err)
+	}
+	return v, nil
+}
+
+// FindDeprecatedAPIs checks every resource of a rendered manifest against the
+// bundled deprecation table for the given cluster version.
+func FindDeprecatedAPIs(manifest string, kubeVersion *semver.Version) []DeprecationFinding {
+	var findings []DeprecationFinding
+	for _, resource := range decodeManifest(manifest) {
+		for _, deprecation := range apiDeprecations {
+			if deprecation.APIVersion != resource.APIVersion() || deprecation.Kind != resource.Kind() {
+				continue
+			}
+			deprecated, _ := semver.NewVersion(deprecation.DeprecatedIn)
+			if kubeVersion.LessThan(deprecated) {
+				continue
+			}
+			finding := DeprecationFinding{
+				APIDeprecation: deprecation,
+				Name:           resource.Name(),
+				Source:         resource.Source,
+			}
+			if deprecation.RemovedIn != "" {
+				removed, _ := semver.NewVersion(deprecation.RemovedIn)
+				finding.Removed = !kubeVersion.LessThan(removed)
+			}
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
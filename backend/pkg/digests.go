@@ -0,0 +1,220 @@
+package pkg
+
+import (
+	"chartpaper/internal/db"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/retry"
+)
+
+const (
+	dockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	dockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+)
+
+// registryHost maps an image registry to the host serving its API. Docker Hub
+// images are named docker.io but served from registry-1.docker.io.
+func registryHost(registry string) string {
+	host := registry
+	if u, err := url.Parse(registry); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	host = strings.SplitN(host, "/", 2)[0]
+	if host == "docker.io" || host == "index.docker.io" {
+		return "registry-1.docker.io"
+	}
+	return host
+}
+
+func isPlainHTTPRegistry(host string) bool {
+	name := strings.SplitN(host, ":", 2)[0]
+	return name == "localhost" || name == "127.0.0.1"
+}
+
+// registryCredentials matches registry hosts against the stored registry configs.
+func registryCredentials(configs []db.RegistryConfig) auth.CredentialFunc {
+	return func(ctx context.Context, hostport string) (auth.Credential, error) {
+		for _, config := range configs {
+			if registryHost(config.RegistryUrl) == hostport && config.Username.Valid {
+				return auth.Credential{
+					Username: config.Username.String,
+					Password: config.Password.String,
+				}, nil
+			}
+		}
+		return auth.EmptyCredential, nil
+	}
+}
+
+// ResolveImageDigest asks the registry's manifest API which digest a tag points
+// to. For multi-platform images the platform list comes from the index, and the
+// size and creation time from its linux/amd64 entry (or the first one).
+func ResolveImageDigest(ctx context.Context, registry, repository, tag string, credential auth.CredentialFunc) (ImageDigest, error) {
+	result := ImageDigest{Registry: registry, Repository: repository, Tag: tag, Platforms: []string{}}
+
+	host := registryHost(registry)
+	repo, err := remote.NewRepository(host + "/" + repository)
+	if err != nil {
+		return result, fmt.Errorf("invalid image reference %s/%s: %v", registry, repository, err)
+	}
+	repo.PlainHTTP = isPlainHTTPRegistry(host)
+	repo.Client = &auth.Client{
+		Client:     retry.DefaultClient,
+		Cache:      auth.NewCache(),
+		Credential: credential,
+	}
+
+	desc, err := repo.Resolve(ctx, tag)
+	if err != nil {
+		return result, fmt.Errorf("failed to resolve %s/%s:%s: %v", registry, repository, tag, err)
+	}
+	result.Digest = desc.Digest.String()
+	result.MediaType = desc.MediaType
+
+	manifestDesc := desc
+	if desc.MediaType == ocispec.MediaTypeImageIndex || desc.MediaType == dockerManifestList {
+		data, err := content.FetchAll(ctx, repo, desc)
+		if err != nil {
+			return result, fmt.Errorf("failed to fetch index: %v", err)
+		}
+		var index ocispec.Index
+		if err := json.Unmarshal(data, &index); err != nil {
+			return result, fmt.Errorf("failed to decode index: %v", err)
+		}
+		if len(index.Manifests) == 0 {
+			return result, nil
+		}
+		manifestDesc = index.Manifests[0]
+		for _, m := range index.Manifests {
+			if m.Platform == nil || m.Platform.OS == "unknown" {
+				continue
+			}
+			platform := m.Platform.OS + "/" + m.Platform.Architecture
+			if m.Platform.Variant != "" {
+				platform += "/" + m.Platform.Variant
+			}
+			result.Platforms = append(result.Platforms, platform)
+			if platform == "linux/amd64" {
+				manifestDesc = m
+			}
+		}
+	}
+
+	if manifestDesc.MediaType != ocispec.MediaTypeImageManifest && manifestDesc.MediaType != dockerManifest {
+		return result, nil
+	}
+	data, err := content.FetchAll(ctx, repo, manifestDesc)
+	if err != nil {
+		return result, fmt.Errorf("failed to fetch manifest: %v", err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return result, fmt.Errorf("failed to decode manifest: %v", err)
+	}
+	result.SizeBytes = manifest.Config.Size
+	for _, layer := range manifest.Layers {
+		result.SizeBytes += layer.Size
+	}
+
+	config, err := content.FetchAll(ctx, repo, manifest.Config)
+	if err != nil {
+		return result, nil
+	}
+	var image ocispec.Image
+	if err := json.Unmarshal(config, &image); err == nil {
+		result.Created = image.Created
+		if len(result.Platforms) == 0 && image.OS != "" {
+			result.Platforms = append(result.Platforms, image.OS+"/"+image.Architecture)
+		}
+	}
+	return result, nil
+}
+
+// digestDrift compares a resolved digest with the one recorded by the previous
+// sync, if any. The tag moved when the digests differ; the digest it moved
+// from is kept as the previous digest until it moves again.
+func digestDrift(recorded *db.ImageDigest, digest string) (changed bool, previous pgtype.Text) {
+	if recorded == nil {
+		return false, pgtype.Text{}
+	}
+	if recorded.Digest != digest {
+		return true, pgtype.Text{String: recorded.Digest, Valid: true}
+	}
+	return false, recorded.PreviousDigest
+}
+
+// ResolveImageDigests resolves every tagged image stored for chartName, or for
+// all charts when chartName is empty, and records the digests. A tag is flagged
+// when its digest differs from the one recorded by the previous sync.
+func ResolveImageDigests(database *pgxpool.Pool, chartName string) ([]ImageDigest, error) {
+	ctx := context.Background()
+	queries := db.New(database)
+
+	configs, err := queries.ListRegistryConfigs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load registry configs: %v", err)
+	}
+	credential := registryCredentials(configs)
+
+	tags, err := queries.ListDistinctImageTags(ctx, pgtype.Text{String: chartName, Valid: chartName != ""})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %v", err)
+	}
+
+	results := make([]ImageDigest, 0, len(tags))
+	for _, row := range tags {
+		resolved, err := ResolveImageDigest(ctx, row.Registry, row.Repository, row.Tag.String, credential)
+		if err != nil {
+			log.Printf("⚠️  Warning: %v\n", err)
+			resolved.Error = err.Error()
+			results = append(results, resolved)
+			continue
+		}
+
+		var recorded *db.ImageDigest
+		if previous, err := queries.GetImageDigest(ctx, db.GetImageDigestParams{Registry: row.Registry, Repository: row.Repository, Tag: row.Tag.String}); err == nil {
+			recorded = &previous
+		}
+		changed, previousDigest := digestDrift(recorded, resolved.Digest)
+
+		platformsJSON, _ := json.Marshal(resolved.Platforms)
+		created := pgtype.Timestamp{}
+		if resolved.Created != nil {
+			created = pgtype.Timestamp{Time: *resolved.Created, Valid: true}
+		}
+		stored, err := queries.UpsertImageDigest(ctx, db.UpsertImageDigestParams{
+			Registry:       row.Registry,
+			Repository:     row.Repository,
+			Tag:            row.Tag.String,
+			Digest:         resolved.Digest,
+			MediaType:      pgtype.Text{String: resolved.MediaType, Valid: resolved.MediaType != ""},
+			Platforms:      pgtype.Text{String: string(platformsJSON), Valid: true},
+			SizeBytes:      pgtype.Int8{Int64: resolved.SizeBytes, Valid: resolved.SizeBytes > 0},
+			ImageCreatedAt: created,
+			PreviousDigest: previousDigest,
+			DigestChanged:  changed,
+		})
+		if err != nil {
+			resolved.Error = fmt.Sprintf("failed to store digest: %v", err)
+		} else {
+			resolved.DigestChanged = stored.DigestChanged
+			resolved.Previous = stored.PreviousDigest.String
+			if stored.DigestChanged {
+				log.Printf("🔀 Tag %s/%s:%s moved from %s to %s\n", row.Registry, row.Repository, row.Tag.String, stored.PreviousDigest.String, stored.Digest)
+			}
+		}
+		results = append(results, resolved)
+	}
+	return results, nil
+}
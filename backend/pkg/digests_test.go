@@ -0,0 +1,243 @@
+package pkg
+
+import (
+	"chartpaper/internal/db"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestDigestDrift(t *testing.T) {
+	tests := []struct {
+		name         string
+		recorded     *db.ImageDigest
+		digest       string
+		wantChanged  bool
+		wantPrevious pgtype.Text
+	}{
+		{
+			name:   "first sync",
+			digest: "sha256:aaa",
+		},
+		{
+			name:     "unchanged tag",
+			recorded: &db.ImageDigest{Digest: "sha256:aaa"},
+			digest:   "sha256:aaa",
+		},
+		{
+			name:         "moved tag",
+			recorded:     &db.ImageDigest{Digest: "sha256:aaa"},
+			digest:       "sha256:bbb",
+			wantChanged:  true,
+			wantPrevious: pgtype.Text{String: "sha256:aaa", Valid: true},
+		},
+		{
+			name: "moved again",
+			recorded: &db.ImageDigest{
+				Digest:         "sha256:bbb",
+				PreviousDigest: pgtype.Text{String: "sha256:aaa", Valid: true},
+				DigestChanged:  true,
+			},
+			digest:       "sha256:ccc",
+			wantChanged:  true,
+			wantPrevious: pgtype.Text{String: "sha256:bbb", Valid: true},
+		},
+		{
+			name: "unchanged after a move keeps the previous digest",
+			recorded: &db.ImageDigest{
+				Digest:         "sha256:bbb",
+				PreviousDigest: pgtype.Text{String: "sha256:aaa", Valid: true},
+				DigestChanged:  true,
+			},
+			digest:       "sha256:bbb",
+			wantPrevious: pgtype.Text{String: "sha256:aaa", Valid: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed, previous := digestDrift(tt.recorded, tt.digest)
+			if changed != tt.wantChanged {
+				t.Errorf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if previous != tt.wantPrevious {
+				t.Errorf("previous = %+v, want %+v", previous, tt.wantPrevious)
+			}
+		})
+	}
+}
+
+// testRegistry serves OCI manifests and blobs of a single repository over the
+// distribution API, enough for ResolveImageDigest.
+type testRegistry struct {
+	repository string
+	content    map[string]testContent
+	tags       map[string]string
+}
+
+type testContent struct {
+	mediaType string
+	data      []byte
+}
+
+// add stores v as JSON and returns its descriptor.
+func (r *testRegistry) add(mediaType string, v interface{}) map[string]interface{} {
+	data, _ := json.Marshal(v)
+	sum := sha256.Sum256(data)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	r.content[digest] = testContent{mediaType: mediaType, data: data}
+	return map[string]interface{}{"mediaType": mediaType, "digest": digest, "size": len(data)}
+}
+
+// image stores a config and a manifest with layers of the given sizes and
+// returns the manifest's descriptor along with the image size the manifest
+// adds up to.
+func (r *testRegistry) image(os, arch, created string, layerSizes ...int64) (map[string]interface{}, int64) {
+	config := r.add(ocispec.MediaTypeImageConfig, map[string]interface{}{
+		"created":      created,
+		"os":           os,
+		"architecture": arch,
+		"rootfs":       map[string]interface{}{"type": "layers", "diff_ids": []string{}},
+	})
+	size := int64(config["size"].(int))
+	var layers []map[string]interface{}
+	for i, layerSize := range layerSizes {
+		size += layerSize
+		layers = append(layers, map[string]interface{}{
+			"mediaType": ocispec.MediaTypeImageLayerGzip,
+			"digest":    fmt.Sprintf("sha256:%064x", i+1),
+			"size":      layerSize,
+		})
+	}
+	return r.add(ocispec.MediaTypeImageManifest, map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     ocispec.MediaTypeImageManifest,
+		"config":        config,
+		"layers":        layers,
+	}), size
+}
+
+func (r *testRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	prefix := "/v2/" + r.repository + "/"
+	rest, ok := strings.CutPrefix(req.URL.Path, prefix)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	kind, ref, _ := strings.Cut(rest, "/")
+	if digest, ok := r.tags[ref]; ok && kind == "manifests" {
+		ref = digest
+	}
+	found, ok := r.content[ref]
+	if !ok || (kind != "manifests" && kind != "blobs") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", found.mediaType)
+	w.Header().Set("Docker-Content-Digest", ref)
+	w.Header().Set("Content-Length", strconv.Itoa(len(found.data)))
+	if req.Method == http.MethodHead {
+		return
+	}
+	w.Write(found.data)
+}
+
+func TestResolveImageDigest(t *testing.T) {
+	const created = "2025-10-01T12:00:00Z"
+	tests := []struct {
+		name          string
+		setup         func(r *testRegistry) (tagged map[string]interface{}, size int64)
+		wantMediaType string
+		wantPlatforms []string
+		wantCreated   string
+	}{
+		{
+			name: "single manifest",
+			setup: func(r *testRegistry) (map[string]interface{}, int64) {
+				return r.image("linux", "amd64", created, 100, 200)
+			},
+			wantMediaType: ocispec.MediaTypeImageManifest,
+			wantPlatforms: []string{"linux/amd64"},
+			wantCreated:   created,
+		},
+		{
+			name: "index prefers linux/amd64 and skips attestations",
+			setup: func(r *testRegistry) (map[string]interface{}, int64) {
+				arm, _ := r.image("linux", "arm64", "2025-09-01T00:00:00Z", 10)
+				arm["platform"] = map[string]interface{}{"os": "linux", "architecture": "arm64", "variant": "v8"}
+				amd, size := r.image("linux", "amd64", created, 1000, 2000)
+				amd["platform"] = map[string]interface{}{"os": "linux", "architecture": "amd64"}
+				attestation, _ := r.image("unknown", "unknown", created, 5)
+				attestation["platform"] = map[string]interface{}{"os": "unknown", "architecture": "unknown"}
+				return r.add(ocispec.MediaTypeImageIndex, map[string]interface{}{
+					"schemaVersion": 2,
+					"mediaType":     ocispec.MediaTypeImageIndex,
+					"manifests":     []map[string]interface{}{arm, amd, attestation},
+				}), size
+			},
+			wantMediaType: ocispec.MediaTypeImageIndex,
+			wantPlatforms: []string{"linux/arm64/v8", "linux/amd64"},
+			wantCreated:   created,
+		},
+		{
+			name: "index without linux/amd64 uses its first entry",
+			setup: func(r *testRegistry) (map[string]interface{}, int64) {
+				arm, size := r.image("linux", "arm64", created, 40, 2)
+				arm["platform"] = map[string]interface{}{"os": "linux", "architecture": "arm64"}
+				s390x, _ := r.image("linux", "s390x", "2025-09-01T00:00:00Z", 7)
+				s390x["platform"] = map[string]interface{}{"os": "linux", "architecture": "s390x"}
+				return r.add(ocispec.MediaTypeImageIndex, map[string]interface{}{
+					"schemaVersion": 2,
+					"mediaType":     ocispec.MediaTypeImageIndex,
+					"manifests":     []map[string]interface{}{arm, s390x},
+				}), size
+			},
+			wantMediaType: ocispec.MediaTypeImageIndex,
+			wantPlatforms: []string{"linux/arm64", "linux/s390x"},
+			wantCreated:   created,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := &testRegistry{repository: "team/app", content: map[string]testContent{}, tags: map[string]string{}}
+			desc, wantSize := tt.setup(registry)
+			registry.tags["1.0"] = desc["digest"].(string)
+			server := httptest.NewServer(registry)
+			defer server.Close()
+			host := strings.TrimPrefix(server.URL, "http://")
+
+			resolved, err := ResolveImageDigest(context.Background(), host, "team/app", "1.0", registryCredentials(nil))
+			if err != nil {
+				t.Fatalf("ResolveImageDigest: %v", err)
+			}
+			if resolved.Digest != desc["digest"] {
+				t.Errorf("digest = %s, want %s", resolved.Digest, desc["digest"])
+			}
+			if resolved.MediaType != tt.wantMediaType {
+				t.Errorf("media type = %s, want %s", resolved.MediaType, tt.wantMediaType)
+			}
+			if !reflect.DeepEqual(resolved.Platforms, tt.wantPlatforms) {
+				t.Errorf("platforms = %v, want %v", resolved.Platforms, tt.wantPlatforms)
+			}
+			if resolved.SizeBytes != wantSize {
+				t.Errorf("size = %d, want %d", resolved.SizeBytes, wantSize)
+			}
+			if resolved.Created == nil || resolved.Created.Format(time.RFC3339) != tt.wantCreated {
+				t.Errorf("created = %v, want %s", resolved.Created, tt.wantCreated)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package pkg
+
+import (
+	"chartpaper/internal/db"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"helm.sh/helm/v3/pkg/repo"
+	"sigs.k8s.io/yaml"
+)
+
+// splitRepoChartURL splits an HTTP chart URL built as repository + "/" + name.
+func splitRepoChartURL(chartURL string) (repoURL, name string) {
+	chartURL = strings.TrimSuffix(chartURL, "/")
+	i := strings.LastIndex(chartURL, "/")
+	if i < 0 {
+		return "", chartURL
+	}
+	return chartURL[:i], chartURL[i+1:]
+}
+
+// ListPublishedVersions lists every version published for a chart: the semver
+// tags of its OCI repository, or its entries in the repository's index.yaml.
+func ListPublishedVersions(database *pgxpool.Pool, chartURL string) ([]string, error) {
+	if strings.HasPrefix(chartURL, "oci://") {
+		registryClient, err := newRegistryClient(database)
+		if err != nil {
+			return nil, err
+		}
+		ref := strings.TrimPrefix(chartURL, "oci://")
+		if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+			ref = ref[:i]
+		}
+		tags, err := registryClient.Tags(ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags of %s: %v", chartURL, err)
+		}
+		return tags, nil
+	}
+
+	if !strings.HasPrefix(chartURL, "http://") && !strings.HasPrefix(chartURL, "https://") {
+		return nil, fmt.Errorf("cannot list versions of %s, expected an oci:// or http(s) chart URL", chartURL)
+	}
+	repoURL, name := splitRepoChartURL(chartURL)
+	resp, err := http.Get(repoURL + "/index.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to download index of %s: %v", repoURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download index of %s: %s", repoURL, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index of %s: %v", repoURL, err)
+	}
+	var index repo.IndexFile
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("invalid index of %s: %v", repoURL, err)
+	}
+	index.SortEntries()
+
+	entries, ok := index.Entries[name]
+	if !ok {
+		return nil, fmt.Errorf("chart %s not found in %s", name, repoURL)
+	}
+	versions := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		versions = append(versions, entry.Version)
+	}
+	return versions, nil
+}
+
+// SelectVersions picks the versions to import, newest first: those matching
+// constraint when it is set, at most limit of them when limit is positive.
+// Tags that are not semver are ignored, as are prereleases unless asked for.
+func SelectVersions(versions []string, constraint string, limit int, includePrereleases bool) ([]string, error) {
+	var constraints *semver.Constraints
+	if constraint != "" {
+		var err error
+		if constraints, err = semver.NewConstraint(constraint); err != nil {
+			return nil, fmt.Errorf("invalid version range %q: %v", constraint, err)
+		}
+	}
+
+	var parsed []*semver.Version
+	for _, version := range versions {
+		v, err := semver.NewVersion(version)
+		if err != nil {
+			continue
+		}
+		if v.Prerelease() != "" && !includePrereleases {
+			continue
+		}
+		if constraints != nil && !constraints.Check(v) {
+			continue
+		}
+		parsed = append(parsed, v)
+	}
+	sort.Sort(sort.Reverse(semver.Collection(parsed)))
+
+	selected := []string{}
+	for _, v := range parsed {
+		if limit > 0 && len(selected) >= limit {
+			break
+		}
+		selected = append(selected, v.Original())
+	}
+	return selected, nil
+}
+
+// ImportChartVersion pulls one published version of a chart and imports it
+// with its default values through the same pipeline as a fetch.
+func ImportChartVersion(database *pgxpool.Pool, chartURL, version, kubeVersion string, apiVersions []string) (*db.Chart, error) {
+	chartUtils, err := NewAuthenticatedChartUtils(database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize chart utils: %v", err)
+	}
+	imported, err := ImportChart(database, chartUtils, ChartRequest{
+		ChartURL:    chartURL,
+		Version:     version,
+		ValuesPath:  "values",
+		SetValues:   []string{},
+		KubeVersion: kubeVersion,
+		APIVersions: apiVersions,
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	return imported.Stored, nil
+}
+
+// BackfillChartHistory imports the selected published versions of a stored
+// chart that are not stored yet, using the latest version's chart URL and
+// render settings.
+func BackfillChartHistory(database *pgxpool.Pool, chart db.Chart, constraint string, limit int, includePrereleases bool) (*HistoryBackfill, error) {
+	ctx := context.Background()
+	queries := db.New(database)
+
+	published, err := ListPublishedVersions(database, chart.ChartUrl)
+	if err != nil {
+		return nil, err
+	}
+	selected, err := SelectVersions(published, constraint, limit, includePrereleases)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &HistoryBackfill{
+		Chart:     chart.Name,
+		Published: len(published),
+		Selected:  selected,
+		Imported:  []string{},
+		Skipped:   []string{},
+		Failed:    []HistoryImportFailure{},
+	}
+	for _, version := range selected {
+		_, err := queries.GetChartVersion(ctx, db.GetChartVersionParams{Name: chart.Name, Version: version})
+		if err == nil {
+			result.Skipped = append(result.Skipped, version)
+			continue
+		}
+
+		log.Printf("📜 Importing %s v%s from %s\n", chart.Name, version, chart.ChartUrl)
+		if _, err := ImportChartVersion(database, chart.ChartUrl, version, chart.KubeVersion.String, DecodeStringList(chart.ApiVersions)); err != nil {
+			log.Printf("⚠️  Could not import %s v%s: %v\n", chart.Name, version, err)
+			result.Failed = append(result.Failed, HistoryImportFailure{Version: version, Error: err.Error()})
+			continue
+		}
+		result.Imported = append(result.Imported, version)
+	}
+	return result, nil
+}
@@ -0,0 +1,68 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectVersions(t *testing.T) {
+	published := []string{"1.0.0", "1.1.0", "v1.2.0", "1.10.0", "2.0.0-rc.1", "2.0.0", "latest", "2.1.0"}
+
+	tests := []struct {
+		name               string
+		constraint         string
+		limit              int
+		includePrereleases bool
+		want               []string
+		wantErr            bool
+	}{
+		{
+			name: "every stable semver tag, newest first",
+			want: []string{"2.1.0", "2.0.0", "1.10.0", "v1.2.0", "1.1.0", "1.0.0"},
+		},
+		{
+			name:       "range",
+			constraint: ">=1.1.0 <2.0.0",
+			want:       []string{"1.10.0", "v1.2.0", "1.1.0"},
+		},
+		{
+			name:  "limit keeps the newest",
+			limit: 2,
+			want:  []string{"2.1.0", "2.0.0"},
+		},
+		{
+			name:       "range and limit",
+			constraint: "^1.0.0",
+			limit:      2,
+			want:       []string{"1.10.0", "v1.2.0"},
+		},
+		{
+			name:               "prereleases when asked for",
+			constraint:         ">=2.0.0-0",
+			includePrereleases: true,
+			want:               []string{"2.1.0", "2.0.0", "2.0.0-rc.1"},
+		},
+		{
+			name:       "nothing in range",
+			constraint: ">=3.0.0",
+			want:       []string{},
+		},
+		{
+			name:       "invalid range",
+			constraint: "not a range",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectVersions(published, tt.constraint, tt.limit, tt.includePrereleases)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("versions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package pkg
+
+import (
+	"chartpaper/internal/db"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ExtractContainerImages lists every container and init container of the
+// workloads in a rendered manifest along with its parsed image reference.
+func ExtractContainerImages(manifest string) []ContainerImage {
+	var images []ContainerImage
+	for _, resource := range decodeManifest(manifest) {
+		spec := podSpec(resource)
+		if spec == nil {
+			continue
+		}
+		containers, initCount := podContainers(spec)
+		for i, container := range containers {
+			ref := nestedString(container, "image")
+			if ref == "" {
+				continue
+			}
+			registry, repository, tag, digest := ParseImageReference(ref)
+			images = append(images, ContainerImage{
+				Workload:      resource.Name(),
+				WorkloadKind:  resource.Kind(),
+				Container:     nestedString(container, "name"),
+				InitContainer: i < initCount,
+				Image:         ref,
+				Registry:      registry,
+				Repository:    repository,
+				Tag:           tag,
+				Digest:        digest,
+				Source:        resource.Source,
+				Subchart:      subchartFromSource(resource.Source),
+				Enabled:       true,
+			})
+		}
+	}
+	return images
+}
+
+// ParseImageReference splits an image reference into registry, repository,
+// tag and digest using Docker's normalization: no registry means docker.io,
+// single-name Docker Hub images live under library/, and a reference with
+// neither tag nor digest implies latest.
+func ParseImageReference(ref string) (registry, repository, tag, digest string) {
+	name := strings.TrimSpace(ref)
+	if i := strings.Index(name, "@"); i >= 0 {
+		digest = name[i+1:]
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i >= 0 && !strings.Contains(name[i:], "/") {
+		tag = name[i+1:]
+		name = name[:i]
+	}
+
+	registry = "docker.io"
+	repository = name
+	if i := strings.Index(name, "/"); i >= 0 {
+		first := name[:i]
+		if strings.ContainsAny(first, ".:") || first == "localhost" {
+			registry = first
+			repository = name[i+1:]
+		}
+	}
+	if registry == "index.docker.io" {
+		registry = "docker.io"
+	}
+	if registry == "docker.io" && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	if tag == "" && digest == "" {
+		tag = "latest"
+	}
+	return registry, repository, tag, digest
+}
+
+// subchartFromSource turns a template path such as
+// "app/charts/redis/charts/common/templates/x.yaml" into the subchart path
+// "redis/common". Templates of the chart itself yield "".
+func subchartFromSource(source string) string {
+	chartPath := source
+	if i := strings.Index(chartPath, "/templates/"); i >= 0 {
+		chartPath = chartPath[:i]
+	}
+	parts := strings.Split(chartPath, "/charts/")
+	if len(parts) < 2 {
+		return ""
+	}
+	return strings.Join(parts[1:], "/")
+}
+
+// DisabledSubchartImages returns the images stored for the default values of
+// chartID that belong to the given subcharts, marked disabled. A profile
+// render drops the subcharts it disables, so these stand in for them.
+func DisabledSubchartImages(ctx context.Context, queries *db.Queries, chartID int32, subcharts []string) ([]ContainerImage, error) {
+	if len(subcharts) == 0 {
+		return nil, nil
+	}
+	rows, err := queries.GetChartImages(ctx, chartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load images: %v", err)
+	}
+	var images []ContainerImage
+	for _, row := range rows {
+		for _, subchart := range subcharts {
+			if row.Subchart.String != subchart && !strings.HasPrefix(row.Subchart.String, subchart+"/") {
+				continue
+			}
+			images = append(images, ContainerImage{
+				Workload:      row.Workload.String,
+				WorkloadKind:  row.WorkloadKind.String,
+				Container:     row.ContainerName.String,
+				InitContainer: row.InitContainer,
+				Image:         row.Image,
+				Registry:      row.Registry,
+				Repository:    row.Repository,
+				Tag:           row.Tag.String,
+				Digest:        row.Digest.String,
+				Subchart:      row.Subchart.String,
+				Enabled:       false,
+			})
+			break
+		}
+	}
+	return images, nil
+}
+
+// StoreImages replaces the image inventory of a chart version, either for the
+// default values (profileID not valid) or for a single values profile.
+func StoreImages(ctx context.Context, queries *db.Queries, chartID int32, chartVersion string, profileID pgtype.Int4, images []ContainerImage) error {
+	var err error
+	if profileID.Valid {
+		err = queries.DeleteChartProfileImages(ctx, db.DeleteChartProfileImagesParams{ChartID: chartID, ProfileID: profileID})
+	} else {
+		err = queries.DeleteChartImages(ctx, chartID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to clear images: %v", err)
+	}
+
+	for _, image := range images {
+		err := queries.CreateImage(ctx, db.CreateImageParams{
+			ChartID:       chartID,
+			ProfileID:     profileID,
+			Image:         image.Image,
+			Registry:      image.Registry,
+			Repository:    image.Repository,
+			Tag:           pgtype.Text{String: image.Tag, Valid: image.Tag != ""},
+			Digest:        pgtype.Text{String: image.Digest, Valid: image.Digest != ""},
+			ChartVersion:  chartVersion,
+			Workload:      pgtype.Text{String: image.Workload, Valid: image.Workload != ""},
+			WorkloadKind:  pgtype.Text{String: image.WorkloadKind, Valid: image.WorkloadKind != ""},
+			ContainerName: pgtype.Text{String: image.Container, Valid: image.Container != ""},
+			InitContainer: image.InitContainer,
+			Subchart:      pgtype.Text{String: image.Subchart, Valid: image.Subchart != ""},
+			Enabled:       image.Enabled,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to store image %s: %v", image.Image, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package pkg
+
+import (
+	"chartpaper/internal/db"
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/ashupednekar/compose/pkg/charts"
+	"github.com/ashupednekar/compose/pkg/spec"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ChartImport is what importing a chart produced. AttemptID is the parse
+// attempt recorded for a failed or partial import, zero otherwise.
+type ChartImport struct {
+	ChartInfo ChartInfo
+	Apps      []spec.App
+	Stored    *db.Chart
+	AttemptID int32
+}
+
+// ImportChart parses, checks and stores a chart; every fetch goes through it.
+// Failures and partial results are recorded as parse attempts, attemptID being
+// the attempt retried, if any, which is resolved once the chart is stored. A
+// failed parse or store returns a *ParseError, values rejected by the schema
+// a *ValuesValidationError and a deny policy a *PolicyDeniedError.
+func ImportChart(database *pgxpool.Pool, chartUtils *charts.ChartUtils, req ChartRequest, attemptID int32) (*ChartImport, error) {
+	result := &ChartImport{}
+
+	chartInfo, apps, err := SafeParseChart(database, chartUtils, req)
+	if err != nil {
+		log.Printf("❌ Failed to fetch chart %s: %v\n", req.ChartURL, err)
+		parseErr := NewParseError(ParseStageTemplate, err, "")
+		if attempt, recordErr := RecordParseAttempt(database, attemptID, req, nil, parseErr); recordErr != nil {
+			log.Printf("⚠️  Warning: %v\n", recordErr)
+		} else {
+			result.AttemptID = attempt.ID
+		}
+		return result, parseErr
+	}
+	result.ChartInfo = chartInfo
+	result.Apps = apps
+
+	log.Printf("✅ Successfully parsed chart: %s v%s\n", chartInfo.Chart.Name, chartInfo.Chart.Version)
+
+	if err := ValidateRequestValues(chartInfo, req); err != nil {
+		log.Printf("❌ Values rejected for chart %s: %v\n", chartInfo.Chart.Name, err)
+		return result, err
+	}
+
+	if chartInfo.ParseError != nil {
+		log.Printf("⚠️  Chart %s parsed partially: %v\n", chartInfo.Chart.Name, chartInfo.ParseError)
+		if attempt, err := RecordParseAttempt(database, attemptID, req, &chartInfo, chartInfo.ParseError); err != nil {
+			log.Printf("⚠️  Warning: %v\n", err)
+		} else {
+			result.AttemptID = attempt.ID
+			attemptID = attempt.ID
+		}
+	}
+
+	violations, policyIDs, err := EvaluatePolicies(database, chartInfo)
+	if err != nil {
+		log.Printf("⚠️  Warning: failed to evaluate policies: %v\n", err)
+	}
+	result.ChartInfo.Violations = violations
+	if HasDenyViolation(violations) {
+		log.Printf("🚫 Chart %s v%s rejected by policy\n", chartInfo.Chart.Name, chartInfo.Chart.Version)
+		return result, &PolicyDeniedError{Violations: violations}
+	}
+
+	storedChart, err := StoreChartInDB(database, result.ChartInfo, apps, req.ChartURL)
+	if err != nil {
+		log.Printf("❌ Failed to store chart %s: %v\n", chartInfo.Chart.Name, err)
+		// Resolving dependencies fails on their auth and lookup errors too
+		parseErr := NewParseError(ParseStageStore, fmt.Errorf("failed to store chart in database: %v", err), "")
+		if attempt, recordErr := RecordParseAttempt(database, attemptID, req, nil, parseErr); recordErr != nil {
+			log.Printf("⚠️  Warning: %v\n", recordErr)
+		} else {
+			result.AttemptID = attempt.ID
+		}
+		return result, parseErr
+	}
+	result.Stored = storedChart
+	log.Printf("✅ Chart stored in database with ID: %d\n", storedChart.ID)
+
+	if attemptID != 0 && chartInfo.ParseError == nil {
+		if err := db.New(database).ResolveParseAttempt(context.Background(), attemptID); err != nil {
+			log.Printf("⚠️  Warning: failed to resolve parse attempt %d: %v\n", attemptID, err)
+		}
+	}
+
+	if err := StorePolicyViolations(database, storedChart.ID, violations, policyIDs); err != nil {
+		log.Printf("⚠️  Warning: %v\n", err)
+	}
+	RenderAllProfiles(database, *storedChart)
+	if req.ResolveDigests {
+		if _, err := ResolveImageDigests(database, storedChart.Name); err != nil {
+			log.Printf("⚠️  Warning: failed to resolve image digests: %v\n", err)
+		}
+	}
+	return result, nil
+}
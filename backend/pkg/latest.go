@@ -0,0 +1,125 @@
+package pkg
+
+import (
+	"chartpaper/internal/db"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// compareChartVersions orders two stored versions by semver precedence.
+// Versions that aren't valid semver sort below those that are, newest first.
+func compareChartVersions(a, b db.Chart) int {
+	va, errA := semver.NewVersion(a.Version)
+	vb, errB := semver.NewVersion(b.Version)
+	switch {
+	case errA == nil && errB == nil:
+		if c := va.Compare(vb); c != 0 {
+			return c
+		}
+	case errA == nil:
+		return 1
+	case errB == nil:
+		return -1
+	}
+	return a.CreatedAt.Time.Compare(b.CreatedAt.Time)
+}
+
+// SortChartVersions orders stored versions of a chart highest first.
+func SortChartVersions(versions []db.Chart) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return compareChartVersions(versions[i], versions[j]) > 0
+	})
+}
+
+// isPrerelease reports whether a version carries a semver prerelease suffix.
+func isPrerelease(version string) bool {
+	v, err := semver.NewVersion(version)
+	return err == nil && v.Prerelease() != ""
+}
+
+// LatestChartVersion picks the version that should be marked latest: the
+// pinned one if it is still stored, otherwise the highest stable version.
+// Prereleases are only considered when no stable version is stored, unless
+// the chart opted into them.
+func LatestChartVersion(versions []db.Chart, pinned string, includePrereleases bool) (db.Chart, bool) {
+	if len(versions) == 0 {
+		return db.Chart{}, false
+	}
+	for _, version := range versions {
+		if pinned != "" && version.Version == pinned {
+			return version, true
+		}
+	}
+
+	var latest *db.Chart
+	for i := range versions {
+		if !includePrereleases && isPrerelease(versions[i].Version) {
+			continue
+		}
+		if latest == nil || compareChartVersions(versions[i], *latest) > 0 {
+			latest = &versions[i]
+		}
+	}
+	if latest == nil {
+		latest = &versions[0]
+		for i := range versions {
+			if compareChartVersions(versions[i], *latest) > 0 {
+				latest = &versions[i]
+			}
+		}
+	}
+	return *latest, true
+}
+
+// RecomputeLatest marks the latest version of a chart by semver precedence,
+// honouring a pinned version and the chart's prerelease setting. It returns
+// the version now marked latest, or an empty string when no versions are
+// stored.
+func RecomputeLatest(ctx context.Context, queries *db.Queries, chartName string) (string, error) {
+	versions, err := queries.ListChartVersions(ctx, chartName)
+	if err != nil {
+		return "", fmt.Errorf("failed to list versions of %s: %v", chartName, err)
+	}
+
+	var pinned string
+	if pin, err := queries.GetChartPin(ctx, chartName); err == nil {
+		pinned = pin.Version
+	}
+
+	var includePrereleases bool
+	if settings, err := queries.GetChartSettings(ctx, chartName); err == nil {
+		includePrereleases = settings.IncludePrereleases
+	}
+
+	latest, ok := LatestChartVersion(versions, pinned, includePrereleases)
+	if !ok {
+		return "", nil
+	}
+	if latest.IsLatest.Bool {
+		// Nothing to do unless another row is also flagged
+		flagged := 0
+		for _, version := range versions {
+			if version.IsLatest.Bool {
+				flagged++
+			}
+		}
+		if flagged == 1 {
+			return latest.Version, nil
+		}
+	}
+
+	if err := queries.SetLatestVersion(ctx, chartName); err != nil {
+		return "", fmt.Errorf("failed to clear latest version of %s: %v", chartName, err)
+	}
+	err = queries.SetVersionAsLatest(ctx, db.SetVersionAsLatestParams{
+		Name:    chartName,
+		Version: latest.Version,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to mark %s v%s as latest: %v", chartName, latest.Version, err)
+	}
+	return latest.Version, nil
+}
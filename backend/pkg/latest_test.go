@@ -0,0 +1,100 @@
+package pkg
+
+import (
+	"chartpaper/internal/db"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestLatestChartVersion(t *testing.T) {
+	stored := func(versions ...string) []db.Chart {
+		charts := make([]db.Chart, 0, len(versions))
+		created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		for i, version := range versions {
+			charts = append(charts, db.Chart{
+				Name:      "app",
+				Version:   version,
+				CreatedAt: pgtype.Timestamp{Time: created.Add(time.Duration(i) * time.Hour), Valid: true},
+			})
+		}
+		return charts
+	}
+
+	tests := []struct {
+		name               string
+		versions           []db.Chart
+		pinned             string
+		includePrereleases bool
+		want               string
+		wantOK             bool
+	}{
+		{
+			name: "nothing stored",
+		},
+		{
+			name:     "highest by semver, not by import order",
+			versions: stored("1.10.0", "1.9.0", "1.2.3"),
+			want:     "1.10.0",
+			wantOK:   true,
+		},
+		{
+			name:     "pinned wins over higher versions",
+			versions: stored("1.0.0", "2.0.0"),
+			pinned:   "1.0.0",
+			want:     "1.0.0",
+			wantOK:   true,
+		},
+		{
+			name:     "pinned version no longer stored",
+			versions: stored("1.0.0", "2.0.0"),
+			pinned:   "3.0.0",
+			want:     "2.0.0",
+			wantOK:   true,
+		},
+		{
+			name:     "stable wins over a higher prerelease",
+			versions: stored("1.0.0", "1.1.0-rc.1"),
+			want:     "1.0.0",
+			wantOK:   true,
+		},
+		{
+			name:               "prerelease when the chart opted in",
+			versions:           stored("1.0.0", "1.1.0-rc.1"),
+			includePrereleases: true,
+			want:               "1.1.0-rc.1",
+			wantOK:             true,
+		},
+		{
+			name:     "highest prerelease when nothing is stable",
+			versions: stored("2.0.0-beta.2", "2.0.0-beta.10", "2.0.0-alpha.1"),
+			want:     "2.0.0-beta.10",
+			wantOK:   true,
+		},
+		{
+			name:     "non-semver sorts below semver",
+			versions: stored("1.0.0", "nightly"),
+			want:     "1.0.0",
+			wantOK:   true,
+		},
+		{
+			name:     "only non-semver picks the newest import",
+			versions: stored("nightly", "main"),
+			want:     "main",
+			wantOK:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			latest, ok := LatestChartVersion(tt.versions, tt.pinned, tt.includePrereleases)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if latest.Version != tt.want {
+				t.Errorf("latest = %q, want %q", latest.Version, tt.want)
+			}
+		})
+	}
+}
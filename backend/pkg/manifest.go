@@ -0,0 +1,116 @@
+package pkg
+
+import (
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// manifestResource is one rendered Kubernetes object together with the
+// template it was rendered from.
+type manifestResource struct {
+	Source string
+	Object map[string]interface{}
+}
+
+func (r manifestResource) Kind() string {
+	return nestedString(r.Object, "kind")
+}
+
+func (r manifestResource) APIVersion() string {
+	return nestedString(r.Object, "apiVersion")
+}
+
+func (r manifestResource) Name() string {
+	return nestedString(r.Object, "metadata", "name")
+}
+
+// decodeManifest parses a rendered manifest into objects, skipping documents
+// that are empty or not valid YAML.
+func decodeManifest(manifest string) []manifestResource {
+	var resources []manifestResource
+	for _, doc := range splitManifest(manifest) {
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil || len(obj) == 0 {
+			continue
+		}
+		if items, ok := obj["items"].([]interface{}); ok && strings.HasSuffix(nestedString(obj, "kind"), "List") {
+			for _, item := range items {
+				if m, ok := item.(map[string]interface{}); ok {
+					resources = append(resources, manifestResource{Source: manifestSource(doc), Object: m})
+				}
+			}
+			continue
+		}
+		resources = append(resources, manifestResource{Source: manifestSource(doc), Object: obj})
+	}
+	return resources
+}
+
+// RenderedSubcharts returns the top level subcharts that have at least one
+// resource in a rendered manifest.
+func RenderedSubcharts(manifest string) map[string]bool {
+	rendered := map[string]bool{}
+	for _, r := range decodeManifest(manifest) {
+		if subchart := subchartFromSource(r.Source); subchart != "" {
+			rendered[strings.Split(subchart, "/")[0]] = true
+		}
+	}
+	return rendered
+}
+
+// podSpec returns the pod spec embedded in a workload, if the kind has one.
+func podSpec(r manifestResource) map[string]interface{} {
+	switch r.Kind() {
+	case "Pod":
+		return nestedMap(r.Object, "spec")
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "Job":
+		return nestedMap(r.Object, "spec", "template", "spec")
+	case "CronJob":
+		return nestedMap(r.Object, "spec", "jobTemplate", "spec", "template", "spec")
+	}
+	return nil
+}
+
+// podContainers returns the init containers followed by the regular containers of a pod spec.
+func podContainers(spec map[string]interface{}) (containers []map[string]interface{}, initCount int) {
+	for _, c := range nestedSlice(spec, "initContainers") {
+		if m, ok := c.(map[string]interface{}); ok {
+			containers = append(containers, m)
+		}
+	}
+	initCount = len(containers)
+	for _, c := range nestedSlice(spec, "containers") {
+		if m, ok := c.(map[string]interface{}); ok {
+			containers = append(containers, m)
+		}
+	}
+	return containers, initCount
+}
+
+func nestedValue(obj map[string]interface{}, fields ...string) interface{} {
+	var current interface{} = obj
+	for _, field := range fields {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[field]
+	}
+	return current
+}
+
+func nestedMap(obj map[string]interface{}, fields ...string) map[string]interface{} {
+	m, _ := nestedValue(obj, fields...).(map[string]interface{})
+	return m
+}
+
+func nestedSlice(obj map[string]interface{}, fields ...string) []interface{} {
+	s, _ := nestedValue(obj, fields...).([]interface{})
+	return s
+}
+
+func nestedString(obj map[string]interface{}, fields ...string) string {
+	s, _ := nestedValue(obj, fields...).(string)
+	return s
+}
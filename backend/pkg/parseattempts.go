@@ -0,0 +1,187 @@
+package pkg
+
+import (
+	"chartpaper/internal/db"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ashupednekar/compose/pkg/charts"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	ParseStageTemplate = "template"
+	ParseStageParse    = "parse"
+	ParseStageStore    = "store"
+
+	ParseErrorAuth     = "auth"
+	ParseErrorNotFound = "not_found"
+	ParseErrorTemplate = "template"
+	ParseErrorSchema   = "schema_validation"
+	ParseErrorPanic    = "panic"
+	ParseErrorUnknown  = "unknown"
+
+	ParseAttemptFailed   = "failed"
+	ParseAttemptPartial  = "partial"
+	ParseAttemptResolved = "resolved"
+)
+
+func (e *ParseError) Error() string {
+	return e.Message
+}
+
+var (
+	// template: mychart/templates/deployment.yaml:12:20: executing ...
+	templateErrorPattern = regexp.MustCompile(`template: ([^:\s]+):(\d+)(?::\d+)?:`)
+	// parse error at (mychart/templates/deployment.yaml:12): ...
+	templateParsePattern = regexp.MustCompile(`parse error at \(([^:)]+):(\d+)\)`)
+	// YAML parse error on mychart/templates/deployment.yaml: ... line 12: ...
+	yamlErrorPattern = regexp.MustCompile(`YAML parse error on ([^:\s]+):(?:.*?line (\d+))?`)
+)
+
+// NewParseError categorizes err, which the given stage returned. stack is the
+// goroutine stack when err came from a recovered panic.
+func NewParseError(stage string, err error, stack string) *ParseError {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		return parseErr
+	}
+
+	message := err.Error()
+	lower := strings.ToLower(message)
+	e := &ParseError{Stage: stage, Category: ParseErrorUnknown, Message: message}
+	switch {
+	case stack != "":
+		e.Category = ParseErrorPanic
+		e.Stack = stack
+	case strings.Contains(lower, "unauthorized") || strings.Contains(lower, "authentication required") ||
+		strings.Contains(lower, "denied") || strings.Contains(lower, "forbidden") ||
+		strings.Contains(message, "401") || strings.Contains(message, "403"):
+		e.Category = ParseErrorAuth
+	case strings.Contains(lower, "not found") || strings.Contains(lower, "manifest unknown") ||
+		strings.Contains(lower, "name unknown") || strings.Contains(message, "404") ||
+		strings.Contains(lower, "no such file"):
+		e.Category = ParseErrorNotFound
+	case strings.Contains(lower, "values don't meet the specifications of the schema"):
+		e.Category = ParseErrorSchema
+		e.Fields = schemaFieldErrors(message)
+	}
+
+	for _, pattern := range []*regexp.Regexp{templateErrorPattern, templateParsePattern, yamlErrorPattern} {
+		if match := pattern.FindStringSubmatch(message); match != nil {
+			if e.Category == ParseErrorUnknown {
+				e.Category = ParseErrorTemplate
+			}
+			e.File = match[1]
+			e.Line, _ = strconv.Atoi(match[2])
+			break
+		}
+	}
+	return e
+}
+
+func ParseAttemptFromDB(attempt db.ChartParseAttempt) ParseAttempt {
+	req := ChartRequest{
+		ChartURL:       attempt.ChartUrl,
+		Version:        attempt.RequestedVersion.String,
+		ValuesPath:     attempt.ValuesPath.String,
+		SetValues:      DecodeStringList(attempt.SetValues),
+		UseHostNetwork: attempt.UseHostNetwork,
+		KubeVersion:    attempt.KubeVersion.String,
+		APIVersions:    DecodeStringList(attempt.ApiVersions),
+		ResolveDigests: attempt.ResolveDigests,
+	}
+	var fields []ValuesFieldError
+	if attempt.Category == ParseErrorSchema {
+		fields = schemaFieldErrors(attempt.Message)
+	}
+	return ParseAttempt{
+		ID:           attempt.ID,
+		Request:      req,
+		ChartName:    attempt.ChartName.String,
+		ChartVersion: attempt.ChartVersion.String,
+		Status:       attempt.Status,
+		Error: ParseError{
+			Stage:    attempt.Stage,
+			Category: attempt.Category,
+			Message:  attempt.Message,
+			File:     attempt.TemplateFile.String,
+			Line:     int(attempt.TemplateLine.Int32),
+			Stack:    attempt.Stack.String,
+			Fields:   fields,
+		},
+		Retries:   attempt.Retries,
+		CreatedAt: attempt.CreatedAt.Time,
+		UpdatedAt: attempt.UpdatedAt.Time,
+	}
+}
+
+// RecordParseAttempt stores a failed import, or a partial one when chartInfo
+// is set. A non-zero id updates the attempt being retried instead.
+func RecordParseAttempt(database *pgxpool.Pool, id int32, req ChartRequest, chartInfo *ChartInfo, parseErr *ParseError) (db.ChartParseAttempt, error) {
+	ctx := context.Background()
+	queries := db.New(database)
+
+	status := ParseAttemptFailed
+	chartName := charts.ExtractName(req.ChartURL)
+	var chartVersion string
+	if chartInfo != nil {
+		status = ParseAttemptPartial
+		chartName = chartInfo.Chart.Name
+		chartVersion = chartInfo.Chart.Version
+	}
+	templateFile := pgtype.Text{String: parseErr.File, Valid: parseErr.File != ""}
+	templateLine := pgtype.Int4{Int32: int32(parseErr.Line), Valid: parseErr.Line > 0}
+	stack := pgtype.Text{String: parseErr.Stack, Valid: parseErr.Stack != ""}
+
+	if id != 0 {
+		attempt, err := queries.UpdateParseAttempt(ctx, db.UpdateParseAttemptParams{
+			ID:           id,
+			ChartName:    pgtype.Text{String: chartName, Valid: chartName != ""},
+			ChartVersion: pgtype.Text{String: chartVersion, Valid: chartVersion != ""},
+			Status:       status,
+			Stage:        parseErr.Stage,
+			Category:     parseErr.Category,
+			Message:      parseErr.Message,
+			TemplateFile: templateFile,
+			TemplateLine: templateLine,
+			Stack:        stack,
+		})
+		if err != nil {
+			return attempt, fmt.Errorf("failed to update parse attempt %d: %v", id, err)
+		}
+		return attempt, nil
+	}
+
+	setValuesJSON, _ := json.Marshal(req.SetValues)
+	apiVersionsJSON, _ := json.Marshal(req.APIVersions)
+	attempt, err := queries.CreateParseAttempt(ctx, db.CreateParseAttemptParams{
+		ChartUrl:         req.ChartURL,
+		ChartName:        pgtype.Text{String: chartName, Valid: chartName != ""},
+		ChartVersion:     pgtype.Text{String: chartVersion, Valid: chartVersion != ""},
+		ValuesPath:       pgtype.Text{String: req.ValuesPath, Valid: req.ValuesPath != ""},
+		SetValues:        pgtype.Text{String: string(setValuesJSON), Valid: len(req.SetValues) > 0},
+		UseHostNetwork:   req.UseHostNetwork,
+		KubeVersion:      pgtype.Text{String: req.KubeVersion, Valid: req.KubeVersion != ""},
+		ApiVersions:      pgtype.Text{String: string(apiVersionsJSON), Valid: len(req.APIVersions) > 0},
+		Status:           status,
+		Stage:            parseErr.Stage,
+		Category:         parseErr.Category,
+		Message:          parseErr.Message,
+		TemplateFile:     templateFile,
+		TemplateLine:     templateLine,
+		Stack:            stack,
+		RequestedVersion: pgtype.Text{String: req.Version, Valid: req.Version != ""},
+		ResolveDigests:   req.ResolveDigests,
+	})
+	if err != nil {
+		return attempt, fmt.Errorf("failed to record parse attempt for %s: %v", req.ChartURL, err)
+	}
+	return attempt, nil
+}
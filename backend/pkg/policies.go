@@ -0,0 +1,270 @@
+package pkg
+
+import (
+	"chartpaper/internal/db"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	PolicyActionWarn = "warn"
+	PolicyActionDeny = "deny"
+
+	PolicyScopeObject = "object"
+	PolicyScopeChart  = "chart"
+)
+
+// policyEnv declares what a policy expression reads: the rendered object, the
+// containers of its pod spec and their parsed images, and the chart. Chart
+// scoped policies only see the chart.
+func policyEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("object", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("containers", cel.ListType(cel.DynType)),
+		cel.Variable("images", cel.ListType(cel.MapType(cel.StringType, cel.StringType))),
+		cel.Variable("chart", cel.MapType(cel.StringType, cel.DynType)),
+	)
+}
+
+// compilePolicy type-checks a policy expression, which must return a bool.
+func compilePolicy(expression string) (cel.Program, error) {
+	env, err := policyEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %v", err)
+	}
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, fmt.Errorf("invalid expression: %v", issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("expression must return a bool, not %s", ast.OutputType())
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %v", err)
+	}
+	return program, nil
+}
+
+// ValidatePolicy checks a policy's scope and action and that its expression
+// compiles.
+func ValidatePolicy(policy Policy) error {
+	if policy.Scope != PolicyScopeObject && policy.Scope != PolicyScopeChart {
+		return fmt.Errorf("policy scope must be %q or %q", PolicyScopeObject, PolicyScopeChart)
+	}
+	if policy.Action != PolicyActionWarn && policy.Action != PolicyActionDeny {
+		return fmt.Errorf("policy action must be %q or %q", PolicyActionWarn, PolicyActionDeny)
+	}
+	if strings.TrimSpace(policy.Expression) == "" {
+		return fmt.Errorf("policy expression is required")
+	}
+	_, err := compilePolicy(policy.Expression)
+	return err
+}
+
+func PolicyFromDB(policy db.Policy) Policy {
+	return Policy{
+		Name:        policy.Name,
+		Description: policy.Description.String,
+		Scope:       policy.Scope,
+		Expression:  policy.Expression,
+		Message:     policy.Message.String,
+		Action:      policy.Action,
+		Enabled:     policy.Enabled,
+	}
+}
+
+// policyChart is the chart variable: its metadata and declared dependencies.
+func policyChart(chartInfo ChartInfo) map[string]interface{} {
+	dependencies := []interface{}{}
+	for _, dep := range chartInfo.Chart.Dependencies {
+		dependencies = append(dependencies, map[string]interface{}{
+			"name":       dep.Name,
+			"alias":      dep.Alias,
+			"version":    dep.Version,
+			"repository": dep.Repository,
+			"condition":  dep.Condition,
+			"enabled":    dep.Enabled,
+		})
+	}
+	return map[string]interface{}{
+		"name":         chartInfo.Chart.Name,
+		"version":      chartInfo.Chart.Version,
+		"apiVersion":   chartInfo.Chart.APIVersion,
+		"type":         chartInfo.Chart.Type,
+		"dependencies": dependencies,
+	}
+}
+
+// policyObject binds the variables of an object scoped policy for one
+// rendered object.
+func policyObject(r manifestResource, chart map[string]interface{}) map[string]interface{} {
+	containers := []interface{}{}
+	images := []interface{}{}
+	if spec := podSpec(r); spec != nil {
+		all, _ := podContainers(spec)
+		for _, container := range all {
+			containers = append(containers, container)
+			ref := nestedString(container, "image")
+			if ref == "" {
+				continue
+			}
+			registry, repository, tag, digest := ParseImageReference(ref)
+			images = append(images, map[string]string{
+				"container":  nestedString(container, "name"),
+				"image":      ref,
+				"registry":   registry,
+				"repository": repository,
+				"tag":        tag,
+				"digest":     digest,
+			})
+		}
+	}
+	return map[string]interface{}{
+		"object":     r.Object,
+		"containers": containers,
+		"images":     images,
+		"chart":      chart,
+	}
+}
+
+// policyViolation evaluates a policy for one binding. An expression that fails
+// to evaluate counts as a violation, so a deny policy never lets a chart
+// through by erroring.
+func policyViolation(policy db.Policy, program cel.Program, vars map[string]interface{}, resource string) *PolicyViolation {
+	message := policy.Message.String
+	if message == "" {
+		message = policy.Description.String
+	}
+	if message == "" {
+		message = fmt.Sprintf("%s is false", policy.Expression)
+	}
+
+	out, _, err := program.Eval(vars)
+	if err != nil {
+		message = fmt.Sprintf("policy expression failed: %v", err)
+	} else if passed, ok := out.Value().(bool); !ok {
+		message = fmt.Sprintf("policy expression returned %v, not a bool", out.Value())
+	} else if passed {
+		return nil
+	}
+	return &PolicyViolation{
+		Policy:   policy.Name,
+		Action:   policy.Action,
+		Resource: resource,
+		Message:  message,
+	}
+}
+
+// EvaluatePolicies checks a parsed chart against every enabled policy: object
+// scoped expressions against each rendered object, chart scoped ones once.
+// The returned ids give the policy each violation belongs to.
+func EvaluatePolicies(database *pgxpool.Pool, chartInfo ChartInfo) ([]PolicyViolation, []int32, error) {
+	queries := db.New(database)
+	policies, err := queries.ListEnabledPolicies(context.Background())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load policies: %v", err)
+	}
+
+	chart := policyChart(chartInfo)
+	resources := decodeManifest(chartInfo.Manifest)
+
+	var violations []PolicyViolation
+	var policyIDs []int32
+	for _, policy := range policies {
+		program, err := compilePolicy(policy.Expression)
+		if err != nil {
+			log.Printf("⚠️  Skipping policy %s: %v\n", policy.Name, err)
+			continue
+		}
+
+		if policy.Scope == PolicyScopeChart {
+			vars := map[string]interface{}{
+				"object":     map[string]interface{}{},
+				"containers": []interface{}{},
+				"images":     []interface{}{},
+				"chart":      chart,
+			}
+			if violation := policyViolation(policy, program, vars, chartInfo.Chart.Name); violation != nil {
+				violations = append(violations, *violation)
+				policyIDs = append(policyIDs, policy.ID)
+			}
+			continue
+		}
+
+		for _, r := range resources {
+			if violation := policyViolation(policy, program, policyObject(r, chart), r.Kind()+"/"+r.Name()); violation != nil {
+				violations = append(violations, *violation)
+				policyIDs = append(policyIDs, policy.ID)
+			}
+		}
+	}
+	return violations, policyIDs, nil
+}
+
+// HasDenyViolation reports whether any violation should reject the chart.
+func HasDenyViolation(violations []PolicyViolation) bool {
+	for _, violation := range violations {
+		if violation.Action == PolicyActionDeny {
+			return true
+		}
+	}
+	return false
+}
+
+// PolicyDeniedError is returned when a deny policy rejects a chart.
+type PolicyDeniedError struct {
+	Violations []PolicyViolation
+}
+
+func (e *PolicyDeniedError) Error() string {
+	return fmt.Sprintf("chart rejected by policy: %d violations", len(e.Violations))
+}
+
+// StorePolicyViolations replaces the violations recorded for a chart version.
+func StorePolicyViolations(database *pgxpool.Pool, chartID int32, violations []PolicyViolation, policyIDs []int32) error {
+	ctx := context.Background()
+	queries := db.New(database)
+	if err := queries.DeleteChartViolations(ctx, chartID); err != nil {
+		return fmt.Errorf("failed to clear violations: %v", err)
+	}
+	for i, violation := range violations {
+		err := queries.CreatePolicyViolation(ctx, db.CreatePolicyViolationParams{
+			ChartID:    chartID,
+			PolicyID:   policyIDs[i],
+			PolicyName: violation.Policy,
+			Action:     violation.Action,
+			Resource:   pgtype.Text{String: violation.Resource, Valid: violation.Resource != ""},
+			Message:    violation.Message,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to store violation of %s: %v", violation.Policy, err)
+		}
+	}
+	return nil
+}
+
+// LoadPolicyViolations returns the violations stored for a chart version.
+func LoadPolicyViolations(database *pgxpool.Pool, chartID int32) ([]PolicyViolation, error) {
+	queries := db.New(database)
+	rows, err := queries.GetChartViolations(context.Background(), chartID)
+	if err != nil {
+		return nil, err
+	}
+	violations := make([]PolicyViolation, 0, len(rows))
+	for _, row := range rows {
+		violations = append(violations, PolicyViolation{
+			Policy:   row.PolicyName,
+			Action:   row.Action,
+			Resource: row.Resource.String,
+			Message:  row.Message,
+		})
+	}
+	return violations, nil
+}
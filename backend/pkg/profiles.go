@@ -0,0 +1,285 @@
+package pkg
+
+import (
+	"chartpaper/internal/db"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func ProfileFromDB(profile db.ValuesProfile) ValuesProfile {
+	p := ValuesProfile{
+		ChartName:      profile.ChartName,
+		Name:           profile.Name,
+		ValuesFiles:    []string{},
+		SetValues:      []string{},
+		UseHostNetwork: profile.UseHostNetwork.Bool,
+	}
+	if profile.ValuesFiles.Valid {
+		json.Unmarshal([]byte(profile.ValuesFiles.String), &p.ValuesFiles)
+	}
+	if profile.SetValues.Valid {
+		json.Unmarshal([]byte(profile.SetValues.String), &p.SetValues)
+	}
+	p.KubeVersion = profile.KubeVersion.String
+	p.APIVersions = DecodeStringList(profile.ApiVersions)
+	p.ChartVersion = profile.ChartVersion.String
+	return p
+}
+
+// DecodeStringList decodes a nullable JSON array column.
+func DecodeStringList(value pgtype.Text) []string {
+	var list []string
+	if value.Valid {
+		json.Unmarshal([]byte(value.String), &list)
+	}
+	return list
+}
+
+// ChartRequest builds the request rendering chartURL with this profile. Several
+// values files are merged into one temporary file, later files winning like
+// repeated -f flags; the returned cleanup removes it.
+func (p ValuesProfile) ChartRequest(chartURL string) (ChartRequest, func(), error) {
+	req := ChartRequest{
+		ChartURL:       chartURL,
+		SetValues:      p.SetValues,
+		UseHostNetwork: p.UseHostNetwork,
+		KubeVersion:    p.KubeVersion,
+		APIVersions:    p.APIVersions,
+	}
+	cleanup := func() {}
+
+	// "values" stands for the chart defaults, which Helm applies anyway
+	var files []string
+	for _, file := range p.ValuesFiles {
+		if file != "" && file != "values" {
+			files = append(files, file)
+		}
+	}
+
+	switch len(files) {
+	case 0:
+		req.ValuesPath = "values"
+	case 1:
+		req.ValuesPath = files[0]
+	default:
+		merged := map[string]interface{}{}
+		for _, file := range files {
+			vals, err := chartutil.ReadValuesFile(file)
+			if err != nil {
+				return req, cleanup, fmt.Errorf("failed to read values file %s: %v", file, err)
+			}
+			merged = mergeValueMaps(merged, vals)
+		}
+		content, err := chartutil.Values(merged).YAML()
+		if err != nil {
+			return req, cleanup, fmt.Errorf("failed to encode merged values: %v", err)
+		}
+		tmp, err := os.CreateTemp("", fmt.Sprintf("chartpaper-%s-*.yaml", p.Name))
+		if err != nil {
+			return req, cleanup, fmt.Errorf("failed to create merged values file: %v", err)
+		}
+		defer tmp.Close()
+		if _, err := tmp.WriteString(content); err != nil {
+			os.Remove(tmp.Name())
+			return req, cleanup, fmt.Errorf("failed to write merged values file: %v", err)
+		}
+		req.ValuesPath = tmp.Name()
+		cleanup = func() { os.Remove(tmp.Name()) }
+	}
+	return req, cleanup, nil
+}
+
+func mergeValueMaps(base, override map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(base))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range override {
+		if next, ok := v.(map[string]interface{}); ok {
+			if current, ok := out[k].(map[string]interface{}); ok {
+				out[k] = mergeValueMaps(current, next)
+				continue
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// RenderChartProfile renders a stored chart version, pulled at that exact
+// version, with a values profile and stores the manifest, image metadata and
+// apps for that profile.
+func RenderChartProfile(database *pgxpool.Pool, chart db.Chart, profile db.ValuesProfile) (*db.ChartRender, error) {
+	ctx := context.Background()
+	queries := db.New(database)
+
+	chartUtils, err := NewAuthenticatedChartUtils(database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize chart utils: %v", err)
+	}
+
+	p := ProfileFromDB(profile)
+	if err := ValidateChartValues(database, chart.ID, p.ValuesFiles, p.SetValues); err != nil {
+		return nil, err
+	}
+
+	req, cleanup, err := p.ChartRequest(chart.ChartUrl)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	req.Version = chart.Version
+
+	log.Printf("🎛️  Rendering %s v%s with profile %s\n", chart.Name, chart.Version, profile.Name)
+	chartInfo, apps, err := SafeParseChart(database, chartUtils, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render profile %s: %v", profile.Name, err)
+	}
+
+	metadata := ManifestMetadata{ImageTag: "N/A", CanaryTag: "N/A"}
+	if chartInfo.ManifestMetadata != nil {
+		metadata = *chartInfo.ManifestMetadata
+	}
+	containerImagesJSON, _ := json.Marshal(metadata.ContainerImages)
+	ingressPathsJSON, _ := json.Marshal(metadata.IngressPaths)
+	servicePortsJSON, _ := json.Marshal(metadata.ServicePorts)
+
+	disabled := []string{}
+	for _, dep := range chartInfo.Chart.Dependencies {
+		if !dep.Enabled {
+			disabled = append(disabled, dependencyKey(dep))
+		}
+	}
+	disabledJSON, _ := json.Marshal(disabled)
+	apiVersionsJSON, _ := json.Marshal(req.APIVersions)
+
+	render, err := queries.UpsertChartRender(ctx, db.UpsertChartRenderParams{
+		ChartID:              chart.ID,
+		ProfileID:            profile.ID,
+		Manifest:             pgtype.Text{String: chartInfo.Manifest, Valid: chartInfo.Manifest != ""},
+		ImageTag:             pgtype.Text{String: chartInfo.ImageTag, Valid: true},
+		CanaryTag:            pgtype.Text{String: chartInfo.CanaryTag, Valid: true},
+		ContainerImages:      pgtype.Text{String: string(containerImagesJSON), Valid: true},
+		IngressPaths:         pgtype.Text{String: string(ingressPathsJSON), Valid: true},
+		ServicePorts:         pgtype.Text{String: string(servicePortsJSON), Valid: true},
+		DisabledDependencies: pgtype.Text{String: string(disabledJSON), Valid: true},
+		KubeVersion:          pgtype.Text{String: req.KubeVersion, Valid: req.KubeVersion != ""},
+		ApiVersions:          pgtype.Text{String: string(apiVersionsJSON), Valid: len(req.APIVersions) > 0},
+		SecuritySummary:      EncodeSecuritySummary(chartInfo.Security),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store render for profile %s: %v", profile.Name, err)
+	}
+
+	profileID := pgtype.Int4{Int32: profile.ID, Valid: true}
+	images := ExtractContainerImages(chartInfo.Manifest)
+	if disabledImages, err := DisabledSubchartImages(ctx, queries, chart.ID, disabled); err != nil {
+		log.Printf("⚠️  Warning: failed to list disabled images for profile %s: %v\n", profile.Name, err)
+	} else {
+		images = append(images, disabledImages...)
+	}
+	if err := StoreImages(ctx, queries, chart.ID, chart.Version, profileID, images); err != nil {
+		log.Printf("⚠️  Warning: failed to store images for profile %s: %v\n", profile.Name, err)
+	}
+	if err := queries.DeleteChartProfileApps(ctx, db.DeleteChartProfileAppsParams{ChartID: chart.ID, ProfileID: profileID}); err != nil {
+		log.Printf("⚠️  Warning: failed to clear apps for profile %s: %v\n", profile.Name, err)
+	}
+	for _, app := range apps {
+		portsJSON, _ := json.Marshal(app.Ports)
+		configsJSON, _ := json.Marshal(app.Configs)
+		mountsJSON, _ := json.Marshal(app.Mounts)
+
+		_, err = queries.CreateApp(ctx, db.CreateAppParams{
+			ChartID:   chart.ID,
+			Name:      app.Name,
+			Image:     pgtype.Text{String: app.Image, Valid: app.Image != ""},
+			AppType:   pgtype.Text{String: app.Type, Valid: app.Type != ""},
+			Ports:     pgtype.Text{String: string(portsJSON), Valid: len(app.Ports) > 0},
+			Configs:   pgtype.Text{String: string(configsJSON), Valid: len(app.Configs) > 0},
+			Mounts:    pgtype.Text{String: string(mountsJSON), Valid: len(app.Mounts) > 0},
+			ProfileID: profileID,
+		})
+		if err != nil {
+			log.Printf("Warning: failed to store app %s for profile %s: %v\n", app.Name, profile.Name, err)
+		}
+	}
+
+	log.Printf("✅ Rendered %s v%s with profile %s\n", chart.Name, chart.Version, profile.Name)
+	return &render, nil
+}
+
+// RenderAllProfiles re-renders a chart version with every profile saved for
+// its chart name. Failures are logged so one broken profile doesn't block the rest.
+func RenderAllProfiles(database *pgxpool.Pool, chart db.Chart) {
+	queries := db.New(database)
+	profiles, err := queries.ListValuesProfiles(context.Background(), chart.Name)
+	if err != nil {
+		log.Printf("⚠️  Warning: failed to list profiles for %s: %v\n", chart.Name, err)
+		return
+	}
+	for _, profile := range profiles {
+		if _, err := RenderChartProfile(database, chart, profile); err != nil {
+			log.Printf("⚠️  Warning: %v\n", err)
+		}
+	}
+}
+
+// ChartInfoFromRender describes a stored chart version as rendered with a profile.
+func ChartInfoFromRender(chart db.Chart, render db.ChartRender, profile string) ChartInfo {
+	var desc string
+	if chart.Description.Valid {
+		desc = chart.Description.String
+	}
+	metadata := ManifestMetadata{
+		ImageTag:        render.ImageTag.String,
+		CanaryTag:       render.CanaryTag.String,
+		ContainerImages: []string{},
+		IngressPaths:    []string{},
+		ServicePorts:    []string{},
+	}
+	json.Unmarshal([]byte(render.ContainerImages.String), &metadata.ContainerImages)
+	json.Unmarshal([]byte(render.IngressPaths.String), &metadata.IngressPaths)
+	json.Unmarshal([]byte(render.ServicePorts.String), &metadata.ServicePorts)
+	security := DecodeSecuritySummary(render.SecuritySummary)
+	if security == nil {
+		// Rendered before summaries were stored
+		summary := AnalyzeSecurity(render.Manifest.String).Summary
+		security = &summary
+	}
+
+	return ChartInfo{
+		Chart: Chart{
+			Name:        chart.Name,
+			Version:     chart.Version,
+			Description: desc,
+			Type:        chart.Type,
+		},
+		ImageTag:         render.ImageTag.String,
+		CanaryTag:        render.CanaryTag.String,
+		ManifestMetadata: &metadata,
+		Profile:          profile,
+		Security:         security,
+		KubeVersion:      render.KubeVersion.String,
+		APIVersions:      DecodeStringList(render.ApiVersions),
+		Manifest:         render.Manifest.String,
+	}
+}
+
+// DisabledDependencies returns the dependencies a profile render disabled,
+// keyed by alias or name.
+func DisabledDependencies(render db.ChartRender) map[string]bool {
+	var names []string
+	json.Unmarshal([]byte(render.DisabledDependencies.String), &names)
+	disabled := make(map[string]bool, len(names))
+	for _, name := range names {
+		disabled[name] = true
+	}
+	return disabled
+}
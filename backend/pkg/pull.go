@@ -0,0 +1,94 @@
+package pkg
+
+import (
+	"chartpaper/internal/db"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/registry"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/retry"
+)
+
+// newRegistryClient creates a Helm registry client authenticated with the
+// stored registry configs.
+func newRegistryClient(database *pgxpool.Pool) (*registry.Client, error) {
+	queries := db.New(database)
+	configs, err := queries.ListRegistryConfigs(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load registry configs: %v", err)
+	}
+	return registry.NewClient(
+		registry.ClientOptWriter(io.Discard),
+		registry.ClientOptAuthorizer(auth.Client{
+			Client:     retry.DefaultClient,
+			Cache:      auth.NewCache(),
+			Credential: registryCredentials(configs),
+		}),
+	)
+}
+
+// DependencyChartRef maps a Chart.yaml dependency repository to what Helm
+// locates charts by: a full oci:// reference, or a repository URL plus the
+// chart name.
+func DependencyChartRef(repository, name string) (chartRef, repoURL string, err error) {
+	switch {
+	case strings.HasPrefix(repository, "oci://"):
+		return strings.TrimSuffix(repository, "/") + "/" + name, "", nil
+	case strings.HasPrefix(repository, "http://") || strings.HasPrefix(repository, "https://"):
+		return name, repository, nil
+	default:
+		return "", "", fmt.Errorf("dependency %s has no remote repository (%q)", name, repository)
+	}
+}
+
+// LoadChartVersion pulls and loads a chart at version, which may also be a
+// semver range. chartRef is an oci:// reference, a local path, or a chart
+// name within repoURL.
+func LoadChartVersion(database *pgxpool.Pool, chartRef, repoURL, version string) (*chart.Chart, error) {
+	registryClient, err := newRegistryClient(database)
+	if err != nil {
+		return nil, err
+	}
+
+	client := action.NewInstall(&action.Configuration{})
+	client.SetRegistryClient(registryClient)
+	client.Version = version
+	client.RepoURL = repoURL
+
+	path, err := client.LocateChart(chartRef, cli.New())
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull %s %s: %v", chartRef, version, err)
+	}
+	ch, err := loader.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s %s: %v", chartRef, version, err)
+	}
+	return ch, nil
+}
+
+// LoadChartURL pulls the chart a stored chart URL points to at version. The
+// URL is an oci:// reference, whose tag the version replaces, a chart within
+// an HTTP repository, or a local path.
+func LoadChartURL(database *pgxpool.Pool, chartURL, version string) (*chart.Chart, error) {
+	switch {
+	case strings.HasPrefix(chartURL, "oci://"):
+		ref := chartURL
+		if i := strings.LastIndex(ref, ":"); version != "" && i > strings.LastIndex(ref, "/") {
+			ref = ref[:i]
+		}
+		return LoadChartVersion(database, ref, "", version)
+	case strings.HasPrefix(chartURL, "http://") || strings.HasPrefix(chartURL, "https://"):
+		repoURL, name := splitRepoChartURL(chartURL)
+		return LoadChartVersion(database, name, repoURL, version)
+	default:
+		return LoadChartVersion(database, chartURL, "", version)
+	}
+}
@@ -0,0 +1,259 @@
+package pkg
+
+import (
+	"chartpaper/internal/db"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ExtractRoutes lists the routes of the Ingress and Gateway API HTTPRoute
+// objects in a rendered manifest. Rules without a host match any host and
+// are reported with host "*".
+func ExtractRoutes(manifest string) []Route {
+	var routes []Route
+	for _, r := range decodeManifest(manifest) {
+		switch r.Kind() {
+		case "Ingress":
+			routes = append(routes, ingressRoutes(r)...)
+		case "HTTPRoute":
+			routes = append(routes, httpRouteRoutes(r)...)
+		}
+	}
+	return routes
+}
+
+// ingressBackend returns the service and port of a networking.k8s.io/v1 or
+// an older serviceName/servicePort backend.
+func ingressBackend(backend map[string]interface{}) (service, port string) {
+	if service = nestedString(backend, "service", "name"); service != "" {
+		if number := nestedValue(backend, "service", "port", "number"); number != nil {
+			return service, fmt.Sprint(number)
+		}
+		return service, nestedString(backend, "service", "port", "name")
+	}
+	if p := nestedValue(backend, "servicePort"); p != nil {
+		port = fmt.Sprint(p)
+	}
+	return nestedString(backend, "serviceName"), port
+}
+
+func ingressRoutes(r manifestResource) []Route {
+	tlsSecrets := map[string]string{}
+	for _, tls := range nestedSlice(r.Object, "spec", "tls") {
+		tlsMap, _ := tls.(map[string]interface{})
+		for _, host := range nestedSlice(tlsMap, "hosts") {
+			if h, ok := host.(string); ok {
+				tlsSecrets[h] = nestedString(tlsMap, "secretName")
+			}
+		}
+	}
+
+	var routes []Route
+	add := func(host, path, pathType string, backend map[string]interface{}) {
+		service, port := ingressBackend(backend)
+		if service == "" {
+			return
+		}
+		if host == "" {
+			host = "*"
+		}
+		if path == "" {
+			path = "/"
+		}
+		routes = append(routes, Route{
+			Host:      host,
+			Path:      path,
+			PathType:  pathType,
+			TLSSecret: tlsSecrets[host],
+			Service:   service,
+			Port:      port,
+			Kind:      r.Kind(),
+			Resource:  r.Name(),
+		})
+	}
+
+	for _, field := range []string{"defaultBackend", "backend"} {
+		if backend := nestedMap(r.Object, "spec", field); backend != nil {
+			add("", "/", "", backend)
+		}
+	}
+	for _, rule := range nestedSlice(r.Object, "spec", "rules") {
+		ruleMap, _ := rule.(map[string]interface{})
+		host := nestedString(ruleMap, "host")
+		for _, p := range nestedSlice(ruleMap, "http", "paths") {
+			pathMap, _ := p.(map[string]interface{})
+			add(host, nestedString(pathMap, "path"), nestedString(pathMap, "pathType"), nestedMap(pathMap, "backend"))
+		}
+	}
+	return routes
+}
+
+// httpRouteRoutes expands every hostname and path match of an HTTPRoute. TLS
+// is terminated by the Gateway, so no secret is reported.
+func httpRouteRoutes(r manifestResource) []Route {
+	var hostnames []string
+	for _, hostname := range nestedSlice(r.Object, "spec", "hostnames") {
+		if h, ok := hostname.(string); ok {
+			hostnames = append(hostnames, h)
+		}
+	}
+	if len(hostnames) == 0 {
+		hostnames = []string{"*"}
+	}
+
+	var routes []Route
+	for _, rule := range nestedSlice(r.Object, "spec", "rules") {
+		ruleMap, _ := rule.(map[string]interface{})
+
+		type pathMatch struct{ path, pathType string }
+		matches := []pathMatch{{"/", "PathPrefix"}}
+		if ruleMatches := nestedSlice(ruleMap, "matches"); len(ruleMatches) > 0 {
+			matches = nil
+			for _, match := range ruleMatches {
+				matchMap, _ := match.(map[string]interface{})
+				m := pathMatch{nestedString(matchMap, "path", "value"), nestedString(matchMap, "path", "type")}
+				if m.path == "" {
+					m.path = "/"
+				}
+				if m.pathType == "" {
+					m.pathType = "PathPrefix"
+				}
+				matches = append(matches, m)
+			}
+		}
+
+		for _, ref := range nestedSlice(ruleMap, "backendRefs") {
+			refMap, _ := ref.(map[string]interface{})
+			if kind := nestedString(refMap, "kind"); kind != "" && kind != "Service" {
+				continue
+			}
+			var port string
+			if p := nestedValue(refMap, "port"); p != nil {
+				port = fmt.Sprint(p)
+			}
+			for _, host := range hostnames {
+				for _, m := range matches {
+					routes = append(routes, Route{
+						Host:     host,
+						Path:     m.path,
+						PathType: m.pathType,
+						Service:  nestedString(refMap, "name"),
+						Port:     port,
+						Kind:     r.Kind(),
+						Resource: r.Name(),
+					})
+				}
+			}
+		}
+	}
+	return routes
+}
+
+// BuildRouteTable collects the routes of the given chart versions, rendered
+// with their default values and, when includeProfiles is set, with every
+// profile rendered for that version. A version stored only as a subchart
+// vendored by another listed chart is skipped, its routes are already part of
+// the parent's render.
+func BuildRouteTable(ctx context.Context, queries *db.Queries, charts []db.Chart, includeProfiles bool) ([]Route, error) {
+	routes := []Route{}
+
+	vendoredByListed := map[string]bool{}
+	for _, chart := range charts {
+		deps, err := queries.GetChartDependencies(ctx, chart.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load dependencies of %s: %v", chart.Name, err)
+		}
+		for _, dep := range deps {
+			vendoredByListed[dep.DependencyName+"@"+ResolvedDependencyVersion(dep)] = true
+		}
+	}
+
+	add := func(chart db.Chart, profile, manifest string) {
+		for _, route := range ExtractRoutes(manifest) {
+			route.Chart = chart.Name
+			route.Version = chart.Version
+			route.Profile = profile
+			routes = append(routes, route)
+		}
+	}
+
+	for _, chart := range charts {
+		if chart.Vendored && vendoredByListed[chart.Name+"@"+chart.Version] {
+			continue
+		}
+		add(chart, "", chart.Manifest.String)
+		if !includeProfiles {
+			continue
+		}
+		profiles, err := queries.ListValuesProfiles(ctx, chart.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list profiles of %s: %v", chart.Name, err)
+		}
+		for _, profile := range profiles {
+			render, err := queries.GetChartRender(ctx, db.GetChartRenderParams{ChartID: chart.ID, ProfileID: profile.ID})
+			if err != nil {
+				continue
+			}
+			add(chart, profile.Name, render.Manifest.String)
+		}
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Host != routes[j].Host {
+			return routes[i].Host < routes[j].Host
+		}
+		return routes[i].Path < routes[j].Path
+	})
+	return routes, nil
+}
+
+// routePathType maps the Gateway API path match types onto the Ingress ones
+// they behave like, so the same match compares equal across both kinds.
+func routePathType(pathType string) string {
+	if pathType == "PathPrefix" {
+		return "Prefix"
+	}
+	return pathType
+}
+
+// FindRouteConflicts groups routes by host, path and path type and reports
+// those claimed by more than one chart. Renders of the same chart with
+// different profiles are alternatives, not conflicts.
+func FindRouteConflicts(routes []Route) []RouteConflict {
+	type routeKey struct{ host, path, pathType string }
+	grouped := map[routeKey][]Route{}
+	var keys []routeKey
+	for _, route := range routes {
+		key := routeKey{strings.ToLower(route.Host), route.Path, routePathType(route.PathType)}
+		if _, ok := grouped[key]; !ok {
+			keys = append(keys, key)
+		}
+		grouped[key] = append(grouped[key], route)
+	}
+
+	conflicts := []RouteConflict{}
+	for _, key := range keys {
+		charts := map[string]bool{}
+		var names []string
+		for _, route := range grouped[key] {
+			if !charts[route.Chart] {
+				charts[route.Chart] = true
+				names = append(names, route.Chart)
+			}
+		}
+		if len(names) < 2 {
+			continue
+		}
+		sort.Strings(names)
+		conflicts = append(conflicts, RouteConflict{
+			Host:     grouped[key][0].Host,
+			Path:     key.path,
+			PathType: key.pathType,
+			Charts:   names,
+			Routes:   grouped[key],
+		})
+	}
+	return conflicts
+}
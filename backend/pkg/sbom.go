@@ -0,0 +1,403 @@
+package pkg
+
+import (
+	"chartpaper/internal/db"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SBOMNode is a chart or subchart in the stored dependency tree, with the
+// container images its templates render. Alias is set when the parent pulls
+// the subchart in under another name.
+type SBOMNode struct {
+	Name        string
+	Alias       string
+	Version     string
+	Repository  string
+	Description string
+	Enabled     bool
+	Children    []*SBOMNode
+	Images      []db.GetChartImagesRow
+}
+
+// BuildSBOMTree walks the stored dependencies of chart. A dependency that was
+// itself stored as a chart (vendored subcharts are) contributes its own
+// dependencies; images are attached to the subchart whose templates rendered them.
+func BuildSBOMTree(ctx context.Context, queries *db.Queries, chart db.Chart, includeDisabled bool) (*SBOMNode, error) {
+	root := &SBOMNode{
+		Name:        chart.Name,
+		Version:     chart.Version,
+		Repository:  chart.ChartUrl,
+		Description: chart.Description.String,
+		Enabled:     true,
+	}
+	byPath := map[string]*SBOMNode{"": root}
+	if err := addSBOMDependencies(ctx, queries, root, chart.ID, "", byPath, includeDisabled, map[int32]bool{chart.ID: true}); err != nil {
+		return nil, err
+	}
+
+	images, err := queries.GetChartImages(ctx, chart.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load images: %v", err)
+	}
+	for _, image := range images {
+		if !image.Enabled && !includeDisabled {
+			continue
+		}
+		nodePath := image.Subchart.String
+		for byPath[nodePath] == nil {
+			nodePath = path.Dir(nodePath)
+			if nodePath == "." || nodePath == "/" {
+				nodePath = ""
+			}
+		}
+		byPath[nodePath].Images = append(byPath[nodePath].Images, image)
+	}
+	return root, nil
+}
+
+// addSBOMDependencies adds the stored dependencies of chartID under node,
+// keyed in byPath by the alias or name their templates render under. visited
+// holds the versions on the current path, so a cycle ends there while the
+// same chart pulled in under two aliases is expanded under both.
+func addSBOMDependencies(ctx context.Context, queries *db.Queries, node *SBOMNode, chartID int32, nodePath string, byPath map[string]*SBOMNode, includeDisabled bool, visited map[int32]bool) error {
+	deps, err := queries.GetChartDependencies(ctx, chartID)
+	if err != nil {
+		return fmt.Errorf("failed to load dependencies of %s: %v", node.Name, err)
+	}
+	for _, dep := range deps {
+		if !dep.Enabled && !includeDisabled {
+			continue
+		}
+		// Chart.yaml may declare a range, the component is what it resolved to
+		version := ResolvedDependencyVersion(dep)
+		child := &SBOMNode{
+			Name:       dep.DependencyName,
+			Alias:      dep.Alias,
+			Version:    version,
+			Repository: dep.Repository.String,
+			Enabled:    dep.Enabled && node.Enabled,
+		}
+		node.Children = append(node.Children, child)
+		childPath := strings.TrimPrefix(nodePath+"/"+StoredDependencyKey(dep), "/")
+		byPath[childPath] = child
+
+		stored, err := queries.GetChartVersion(ctx, db.GetChartVersionParams{Name: dep.DependencyName, Version: version})
+		if err != nil || visited[stored.ID] {
+			continue
+		}
+		child.Description = stored.Description.String
+		visited[stored.ID] = true
+		err = addSBOMDependencies(ctx, queries, child, stored.ID, childPath, byPath, includeDisabled, visited)
+		delete(visited, stored.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type sbomImage struct {
+	ref     string
+	name    string
+	version string
+	purl    string
+}
+
+func newSBOMImage(image db.GetChartImagesRow) sbomImage {
+	digest := image.Digest.String
+	if digest == "" {
+		digest = image.ResolvedDigest.String
+	}
+	img := sbomImage{
+		name:    image.Registry + "/" + image.Repository,
+		version: image.Tag.String,
+	}
+	img.ref = img.name
+	if image.Tag.String != "" {
+		img.ref += ":" + image.Tag.String
+	}
+	if digest != "" {
+		img.ref += "@" + digest
+		if img.version == "" {
+			img.version = digest
+		}
+		qualifiers := url.Values{}
+		qualifiers.Set("repository_url", img.name)
+		if image.Tag.String != "" {
+			qualifiers.Set("tag", image.Tag.String)
+		}
+		img.purl = fmt.Sprintf("pkg:oci/%s@%s?%s", path.Base(image.Repository), url.PathEscape(digest), qualifiers.Encode())
+	} else {
+		img.purl = fmt.Sprintf("pkg:docker/%s@%s", image.Repository, image.Tag.String)
+		if image.Registry != "docker.io" {
+			img.purl += "?repository_url=" + url.QueryEscape(image.Registry)
+		}
+	}
+	return img
+}
+
+// sbomChartKey identifies a chart node; two aliases of the same chart
+// version are separate components.
+func sbomChartKey(node *SBOMNode) string {
+	key := node.Name + "@" + node.Version
+	if node.Alias != "" {
+		key += "#" + node.Alias
+	}
+	return key
+}
+
+func sbomChartRef(node *SBOMNode) string {
+	return "chart:" + sbomChartKey(node)
+}
+
+type cycloneDXComponent struct {
+	Type               string                 `json:"type"`
+	BOMRef             string                 `json:"bom-ref"`
+	Name               string                 `json:"name"`
+	Version            string                 `json:"version,omitempty"`
+	Description        string                 `json:"description,omitempty"`
+	Purl               string                 `json:"purl,omitempty"`
+	ExternalReferences []cycloneDXExternalRef `json:"externalReferences,omitempty"`
+	Properties         []cycloneDXProperty    `json:"properties,omitempty"`
+}
+
+type cycloneDXExternalRef struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// CycloneDX renders the tree as a CycloneDX 1.5 JSON document.
+func (root *SBOMNode) CycloneDX() map[string]interface{} {
+	var components []cycloneDXComponent
+	var dependencies []cycloneDXDependency
+	seen := map[string]bool{sbomChartRef(root): true}
+
+	chartComponent := func(node *SBOMNode) cycloneDXComponent {
+		component := cycloneDXComponent{
+			Type:        "application",
+			BOMRef:      sbomChartRef(node),
+			Name:        node.Name,
+			Version:     node.Version,
+			Description: node.Description,
+			Properties:  []cycloneDXProperty{{Name: "chartpaper:type", Value: "helm-chart"}},
+		}
+		if node.Repository != "" {
+			component.ExternalReferences = []cycloneDXExternalRef{{Type: "distribution", URL: node.Repository}}
+		}
+		if node.Alias != "" {
+			component.Properties = append(component.Properties, cycloneDXProperty{Name: "chartpaper:alias", Value: node.Alias})
+		}
+		if !node.Enabled {
+			component.Properties = append(component.Properties, cycloneDXProperty{Name: "chartpaper:enabled", Value: "false"})
+		}
+		return component
+	}
+
+	var walk func(node *SBOMNode)
+	walk = func(node *SBOMNode) {
+		dependsOn := []string{}
+		for _, child := range node.Children {
+			dependsOn = append(dependsOn, sbomChartRef(child))
+		}
+		for _, image := range node.Images {
+			img := newSBOMImage(image)
+			if !contains(dependsOn, img.ref) {
+				dependsOn = append(dependsOn, img.ref)
+			}
+			if seen[img.ref] {
+				continue
+			}
+			seen[img.ref] = true
+			components = append(components, cycloneDXComponent{
+				Type:    "container",
+				BOMRef:  img.ref,
+				Name:    img.name,
+				Version: img.version,
+				Purl:    img.purl,
+			})
+			dependencies = append(dependencies, cycloneDXDependency{Ref: img.ref, DependsOn: []string{}})
+		}
+		dependencies = append(dependencies, cycloneDXDependency{Ref: sbomChartRef(node), DependsOn: dependsOn})
+		for _, child := range node.Children {
+			if seen[sbomChartRef(child)] {
+				continue
+			}
+			seen[sbomChartRef(child)] = true
+			components = append(components, chartComponent(child))
+			walk(child)
+		}
+	}
+	walk(root)
+	if components == nil {
+		components = []cycloneDXComponent{}
+	}
+
+	return map[string]interface{}{
+		"bomFormat":    "CycloneDX",
+		"specVersion":  "1.5",
+		"serialNumber": "urn:uuid:" + newUUID(),
+		"version":      1,
+		"metadata": map[string]interface{}{
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+			"tools": map[string]interface{}{
+				"components": []cycloneDXComponent{{Type: "application", BOMRef: "chartpaper", Name: "chartpaper"}},
+			},
+			"component": chartComponent(root),
+		},
+		"components":   components,
+		"dependencies": dependencies,
+	}
+}
+
+type spdxPackage struct {
+	Name                  string            `json:"name"`
+	SPDXID                string            `json:"SPDXID"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	Description           string            `json:"description,omitempty"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+	Comment               string            `json:"comment,omitempty"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+var spdxIDUnsafe = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+func spdxID(kind, ref string) string {
+	return "SPDXRef-" + kind + "-" + strings.Trim(spdxIDUnsafe.ReplaceAllString(ref, "-"), "-")
+}
+
+// SPDX renders the tree as an SPDX 2.3 JSON document.
+func (root *SBOMNode) SPDX() map[string]interface{} {
+	var packages []spdxPackage
+	relationships := []spdxRelationship{}
+	seen := map[string]bool{}
+
+	chartPackage := func(node *SBOMNode) spdxPackage {
+		p := spdxPackage{
+			Name:                  node.Name,
+			SPDXID:                spdxID("Chart", sbomChartKey(node)),
+			VersionInfo:           node.Version,
+			DownloadLocation:      "NOASSERTION",
+			Description:           node.Description,
+			PrimaryPackagePurpose: "APPLICATION",
+		}
+		if node.Repository != "" {
+			p.DownloadLocation = node.Repository
+		}
+		if node.Alias != "" {
+			p.Comment = "Pulled in as " + node.Alias
+		}
+		if !node.Enabled {
+			p.Comment = strings.TrimPrefix(p.Comment+". Disabled by dependency condition or tags", ". ")
+		}
+		return p
+	}
+
+	var walk func(node *SBOMNode)
+	walk = func(node *SBOMNode) {
+		parent := spdxID("Chart", sbomChartKey(node))
+		for _, child := range node.Children {
+			childPkg := chartPackage(child)
+			if !seen[childPkg.SPDXID] {
+				seen[childPkg.SPDXID] = true
+				packages = append(packages, childPkg)
+			}
+			relationships = append(relationships, spdxRelationship{parent, "DEPENDS_ON", childPkg.SPDXID})
+		}
+		linked := map[string]bool{}
+		for _, image := range node.Images {
+			img := newSBOMImage(image)
+			id := spdxID("Image", img.ref)
+			if !seen[id] {
+				seen[id] = true
+				packages = append(packages, spdxPackage{
+					Name:                  img.name,
+					SPDXID:                id,
+					VersionInfo:           img.version,
+					DownloadLocation:      "NOASSERTION",
+					PrimaryPackagePurpose: "CONTAINER",
+					ExternalRefs: []spdxExternalRef{{
+						ReferenceCategory: "PACKAGE-MANAGER",
+						ReferenceType:     "purl",
+						ReferenceLocator:  img.purl,
+					}},
+				})
+			}
+			if !linked[id] {
+				linked[id] = true
+				relationships = append(relationships, spdxRelationship{parent, "DEPENDS_ON", id})
+			}
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+
+	rootPkg := chartPackage(root)
+	seen[rootPkg.SPDXID] = true
+	packages = append(packages, rootPkg)
+	relationships = append(relationships, spdxRelationship{"SPDXRef-DOCUMENT", "DESCRIBES", rootPkg.SPDXID})
+	walk(root)
+
+	name := root.Name + "-" + root.Version
+	return map[string]interface{}{
+		"spdxVersion":       "SPDX-2.3",
+		"dataLicense":       "CC0-1.0",
+		"SPDXID":            "SPDXRef-DOCUMENT",
+		"name":              name,
+		"documentNamespace": "https://chartpaper/spdx/" + url.PathEscape(name) + "-" + newUUID(),
+		"creationInfo": map[string]interface{}{
+			"created":  time.Now().UTC().Format(time.RFC3339),
+			"creators": []string{"Tool: chartpaper"},
+		},
+		"packages":      packages,
+		"relationships": relationships,
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func newUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,340 @@
+package pkg
+
+import (
+	"chartpaper/internal/db"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// severityScores weighs a single finding; findings are ranked by it.
+var severityScores = map[string]int{
+	SeverityLow:      3,
+	SeverityMedium:   10,
+	SeverityHigh:     20,
+	SeverityCritical: 30,
+}
+
+// severityBands is the range of chart scores each level covers.
+var severityBands = map[string][2]int{
+	SeverityLow:      {1, 29},
+	SeverityMedium:   {30, 59},
+	SeverityHigh:     {60, 84},
+	SeverityCritical: {85, 100},
+}
+
+// dangerousCapabilities effectively grant root on the node.
+var dangerousCapabilities = map[string]bool{
+	"ALL": true, "SYS_ADMIN": true, "NET_ADMIN": true, "SYS_PTRACE": true, "SYS_MODULE": true, "DAC_READ_SEARCH": true,
+}
+
+// escalationVerbs let a subject grant itself more permissions than it holds.
+var escalationVerbs = map[string]bool{"escalate": true, "bind": true, "impersonate": true}
+
+type securityAnalysis struct {
+	posture *SecurityPosture
+}
+
+func (a *securityAnalysis) find(severity, resource, subchart, format string, args ...interface{}) {
+	a.posture.Findings = append(a.posture.Findings, SecurityFinding{
+		Severity: severity,
+		Score:    severityScores[severity],
+		Resource: resource,
+		Subchart: subchart,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// AnalyzeSecurity extracts the service accounts, RBAC objects, pod security
+// settings, hostPath mounts and NetworkPolicies of a rendered manifest and
+// scores what they allow.
+func AnalyzeSecurity(manifest string) SecurityPosture {
+	posture := SecurityPosture{
+		ServiceAccounts: []string{},
+		Roles:           []RBACRole{},
+		Bindings:        []RBACBinding{},
+		Workloads:       []WorkloadSecurity{},
+		NetworkPolicies: []string{},
+		Findings:        []SecurityFinding{},
+	}
+	a := &securityAnalysis{posture: &posture}
+
+	for _, r := range decodeManifest(manifest) {
+		resource := r.Kind() + "/" + r.Name()
+		subchart := subchartFromSource(r.Source)
+		switch r.Kind() {
+		case "ServiceAccount":
+			posture.ServiceAccounts = append(posture.ServiceAccounts, r.Name())
+		case "Role", "ClusterRole":
+			a.analyzeRole(r, resource, subchart)
+		case "RoleBinding", "ClusterRoleBinding":
+			a.analyzeBinding(r, resource, subchart)
+		case "NetworkPolicy":
+			posture.NetworkPolicies = append(posture.NetworkPolicies, r.Name())
+		default:
+			if spec := podSpec(r); spec != nil {
+				a.analyzeWorkload(r, spec, resource, subchart)
+			}
+		}
+	}
+
+	if len(posture.Workloads) > 0 && len(posture.NetworkPolicies) == 0 {
+		a.find(SeverityMedium, "", "", "No NetworkPolicy restricts traffic to the chart's workloads")
+	}
+
+	sort.SliceStable(posture.Findings, func(i, j int) bool {
+		return posture.Findings[i].Score > posture.Findings[j].Score
+	})
+	posture.Summary = SummarizeSecurity(posture.Findings)
+	return posture
+}
+
+func stringList(values []interface{}) []string {
+	var list []string
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+func (a *securityAnalysis) analyzeRole(r manifestResource, resource, subchart string) {
+	role := RBACRole{Kind: r.Kind(), Name: r.Name(), Rules: []string{}}
+	clusterWide := r.Kind() == "ClusterRole"
+
+	for _, rule := range nestedSlice(r.Object, "rules") {
+		ruleMap, _ := rule.(map[string]interface{})
+		verbs := stringList(nestedSlice(ruleMap, "verbs"))
+		resources := stringList(nestedSlice(ruleMap, "resources"))
+		groups := stringList(nestedSlice(ruleMap, "apiGroups"))
+		role.Rules = append(role.Rules, fmt.Sprintf("%s %s [%s]", strings.Join(verbs, ","), strings.Join(resources, ","), strings.Join(groups, ",")))
+
+		hasVerb := func(names ...string) bool {
+			for _, verb := range verbs {
+				for _, name := range names {
+					if verb == name || verb == "*" {
+						return true
+					}
+				}
+			}
+			return false
+		}
+		hasResource := func(name string) bool {
+			for _, res := range resources {
+				if res == name || res == "*" {
+					return true
+				}
+			}
+			return false
+		}
+
+		severity := SeverityHigh
+		if clusterWide {
+			severity = SeverityCritical
+		}
+		switch {
+		case hasVerb("*") && hasResource("*"):
+			a.find(severity, resource, subchart, "Grants every verb on every resource")
+			continue
+		case hasResource("*"):
+			a.find(SeverityHigh, resource, subchart, "Grants %s on every resource", strings.Join(verbs, ","))
+		case hasVerb("*"):
+			a.find(SeverityMedium, resource, subchart, "Grants every verb on %s", strings.Join(resources, ","))
+		}
+		for _, verb := range verbs {
+			if escalationVerbs[verb] {
+				a.find(severity, resource, subchart, "Allows %s, which can escalate privileges", verb)
+			}
+		}
+		if hasResource("secrets") && hasVerb("get", "list", "watch") {
+			if clusterWide {
+				a.find(SeverityHigh, resource, subchart, "Reads Secrets in every namespace")
+			} else {
+				a.find(SeverityMedium, resource, subchart, "Reads Secrets")
+			}
+		}
+		if hasResource("pods/exec") && hasVerb("create") {
+			a.find(SeverityHigh, resource, subchart, "Can exec into pods")
+		}
+	}
+	a.posture.Roles = append(a.posture.Roles, role)
+}
+
+func (a *securityAnalysis) analyzeBinding(r manifestResource, resource, subchart string) {
+	binding := RBACBinding{
+		Kind:     r.Kind(),
+		Name:     r.Name(),
+		RoleRef:  nestedString(r.Object, "roleRef", "kind") + "/" + nestedString(r.Object, "roleRef", "name"),
+		Subjects: []string{},
+	}
+	for _, subject := range nestedSlice(r.Object, "subjects") {
+		subjectMap, _ := subject.(map[string]interface{})
+		binding.Subjects = append(binding.Subjects, nestedString(subjectMap, "kind")+"/"+nestedString(subjectMap, "name"))
+	}
+
+	switch nestedString(r.Object, "roleRef", "name") {
+	case "cluster-admin":
+		if r.Kind() == "ClusterRoleBinding" {
+			a.find(SeverityCritical, resource, subchart, "Binds %s to cluster-admin", strings.Join(binding.Subjects, ", "))
+		} else {
+			a.find(SeverityHigh, resource, subchart, "Binds %s to cluster-admin in its namespace", strings.Join(binding.Subjects, ", "))
+		}
+	case "admin", "edit":
+		a.find(SeverityMedium, resource, subchart, "Binds %s to the built-in %s role", strings.Join(binding.Subjects, ", "), nestedString(r.Object, "roleRef", "name"))
+	}
+	a.posture.Bindings = append(a.posture.Bindings, binding)
+}
+
+func (a *securityAnalysis) analyzeWorkload(r manifestResource, spec map[string]interface{}, resource, subchart string) {
+	workload := WorkloadSecurity{
+		Kind:           r.Kind(),
+		Name:           r.Name(),
+		Subchart:       subchart,
+		ServiceAccount: nestedString(spec, "serviceAccountName"),
+	}
+	workload.HostNetwork, _ = nestedValue(spec, "hostNetwork").(bool)
+	workload.HostPID, _ = nestedValue(spec, "hostPID").(bool)
+	workload.HostIPC, _ = nestedValue(spec, "hostIPC").(bool)
+	for _, flag := range []struct {
+		name    string
+		enabled bool
+	}{{"hostNetwork", workload.HostNetwork}, {"hostPID", workload.HostPID}, {"hostIPC", workload.HostIPC}} {
+		if flag.enabled {
+			a.find(SeverityHigh, resource, subchart, "Shares the node's namespace (%s)", flag.name)
+		}
+	}
+
+	for _, volume := range nestedSlice(spec, "volumes") {
+		v, _ := volume.(map[string]interface{})
+		if p := nestedString(v, "hostPath", "path"); p != "" {
+			workload.HostPaths = append(workload.HostPaths, p)
+			severity := SeverityHigh
+			if p == "/" || strings.HasPrefix(p, "/var/run/docker.sock") || strings.HasPrefix(p, "/run/containerd") || strings.HasPrefix(p, "/etc") {
+				severity = SeverityCritical
+			}
+			a.find(severity, resource, subchart, "Mounts host path %s", p)
+		}
+	}
+
+	podNonRoot, _ := nestedValue(spec, "securityContext", "runAsNonRoot").(bool)
+	podUser, podUserSet := nestedValue(spec, "securityContext", "runAsUser").(float64)
+
+	containers, _ := podContainers(spec)
+	for _, container := range containers {
+		name := nestedString(container, "name")
+		sc := nestedMap(container, "securityContext")
+
+		if privileged, _ := nestedValue(sc, "privileged").(bool); privileged {
+			workload.Privileged = append(workload.Privileged, name)
+			a.find(SeverityCritical, resource, subchart, "Container %s runs privileged", name)
+		}
+
+		nonRoot, nonRootSet := nestedValue(sc, "runAsNonRoot").(bool)
+		user, userSet := nestedValue(sc, "runAsUser").(float64)
+		if !nonRootSet {
+			nonRoot = podNonRoot
+		}
+		if !userSet {
+			user, userSet = podUser, podUserSet
+		}
+		if (userSet && user == 0) || (!nonRoot && !userSet) {
+			workload.RunAsRoot = append(workload.RunAsRoot, name)
+			a.find(SeverityLow, resource, subchart, "Container %s may run as root", name)
+		}
+
+		if escalation, set := nestedValue(sc, "allowPrivilegeEscalation").(bool); !set || escalation {
+			workload.PrivilegeEscalation = append(workload.PrivilegeEscalation, name)
+			a.find(SeverityLow, resource, subchart, "Container %s doesn't set allowPrivilegeEscalation: false", name)
+		}
+
+		for _, capability := range stringList(nestedSlice(sc, "capabilities", "add")) {
+			capability = strings.TrimPrefix(strings.ToUpper(capability), "CAP_")
+			workload.Capabilities = append(workload.Capabilities, name+":"+capability)
+			if dangerousCapabilities[capability] {
+				a.find(SeverityHigh, resource, subchart, "Container %s adds capability %s", name, capability)
+			} else {
+				a.find(SeverityLow, resource, subchart, "Container %s adds capability %s", name, capability)
+			}
+		}
+	}
+	a.posture.Workloads = append(a.posture.Workloads, workload)
+}
+
+// SummarizeSecurity scores a set of findings. The level is the severity of
+// the worst finding and the score starts at the bottom of that level's band;
+// the other findings add half their weight but never lift it into the next
+// level, so many minor findings in an umbrella chart don't read as critical.
+func SummarizeSecurity(findings []SecurityFinding) SecuritySummary {
+	summary := SecuritySummary{Level: SeverityLow, Findings: len(findings)}
+	if len(findings) == 0 {
+		return summary
+	}
+	worst, total := findings[0], 0
+	for _, finding := range findings {
+		total += finding.Score
+		if severityScores[finding.Severity] > severityScores[worst.Severity] {
+			worst = finding
+		}
+	}
+	band := severityBands[worst.Severity]
+	summary.Level = worst.Severity
+	summary.Score = band[0] + min((total-worst.Score)/2, band[1]-band[0])
+	return summary
+}
+
+// SubchartSecurity scores only the findings in resources rendered from the
+// named subchart or its own subcharts.
+func SubchartSecurity(findings []SecurityFinding, name string) SecuritySummary {
+	var filtered []SecurityFinding
+	for _, finding := range findings {
+		if finding.Subchart == name || strings.HasPrefix(finding.Subchart, name+"/") {
+			filtered = append(filtered, finding)
+		}
+	}
+	return SummarizeSecurity(filtered)
+}
+
+// DependencySecurity scores a dependency from the parent's findings when it
+// was rendered with the parent, or returns the summary stored with it
+// otherwise, nil when there is none.
+func DependencySecurity(parent SecurityPosture, rendered map[string]bool, dep db.GetChartDependenciesRow) *SecuritySummary {
+	key := StoredDependencyKey(dep)
+	if rendered[key] {
+		summary := SubchartSecurity(parent.Findings, key)
+		return &summary
+	}
+	return DecodeSecuritySummary(dep.SecuritySummary)
+}
+
+// EncodeSecuritySummary encodes a summary for a nullable JSON column.
+func EncodeSecuritySummary(summary *SecuritySummary) pgtype.Text {
+	if summary == nil {
+		return pgtype.Text{}
+	}
+	summaryJSON, _ := json.Marshal(summary)
+	return pgtype.Text{String: string(summaryJSON), Valid: true}
+}
+
+// DecodeSecuritySummary decodes a nullable JSON summary column, nil when it
+// was never stored.
+func DecodeSecuritySummary(value pgtype.Text) *SecuritySummary {
+	if !value.Valid {
+		return nil
+	}
+	var summary SecuritySummary
+	if err := json.Unmarshal([]byte(value.String), &summary); err != nil {
+		return nil
+	}
+	return &summary
+}
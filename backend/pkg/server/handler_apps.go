@@ -0,0 +1,120 @@
+package server
+
+import (
+	"chartpaper/internal/db"
+	"chartpaper/pkg"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// chartApps loads the apps of the requested chart version, the latest unless
+// ?version= is given, as parsed for ?profile= or for the default values.
+func (s *Server) chartApps(c *gin.Context) (db.Chart, []pkg.App, bool) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+	chart, ok := s.chartVersion(c)
+	if !ok {
+		return chart, nil, false
+	}
+
+	render, ok := s.profileRender(c, chart)
+	if !ok {
+		return chart, nil, false
+	}
+	var profileID pgtype.Int4
+	if render != nil {
+		profileID = pgtype.Int4{Int32: render.ProfileID, Valid: true}
+	}
+
+	apps, err := pkg.LoadChartApps(ctx, queries, chart, profileID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return chart, nil, false
+	}
+	return chart, apps, true
+}
+
+func (s *Server) getChartApps(c *gin.Context) {
+	chart, apps, ok := s.chartApps(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"chart":   chart.Name,
+		"version": chart.Version,
+		"profile": c.Query("profile"),
+		"apps":    apps,
+		"count":   len(apps),
+	})
+}
+
+func (s *Server) getChartApp(c *gin.Context) {
+	chart, apps, ok := s.chartApps(c)
+	if !ok {
+		return
+	}
+
+	appName := c.Param("app")
+	for _, app := range apps {
+		if app.Name == appName {
+			c.JSON(http.StatusOK, gin.H{
+				"chart":   chart.Name,
+				"version": chart.Version,
+				"profile": c.Query("profile"),
+				"app":     app,
+			})
+			return
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "App not found", "app": appName, "version": chart.Version})
+}
+
+// getComposeFile exports the apps of a chart version and its enabled
+// dependencies as a docker-compose file. Host network mode follows the
+// profile's useHostNetwork, or ?hostNetwork= without a profile.
+func (s *Server) getComposeFile(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+	chart, ok := s.chartVersion(c)
+	if !ok {
+		return
+	}
+
+	render, ok := s.profileRender(c, chart)
+	if !ok {
+		return
+	}
+	useHostNetwork, _ := strconv.ParseBool(c.Query("hostNetwork"))
+	if render != nil {
+		profile, err := queries.GetValuesProfile(ctx, db.GetValuesProfileParams{ChartName: chart.Name, Name: c.Query("profile")})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		useHostNetwork = profile.UseHostNetwork.Bool
+	}
+
+	file, err := pkg.BuildComposeFile(ctx, queries, chart, render, useHostNetwork)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(file.Services) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No apps were parsed for this chart version", "version": chart.Version})
+		return
+	}
+	out, err := file.YAML()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", chart.Name+"-"+chart.Version+".compose.yaml"))
+	c.Data(http.StatusOK, "application/yaml", out)
+}
@@ -5,6 +5,7 @@ import (
 	"chartpaper/pkg"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -24,6 +25,12 @@ func (s *Server) fetchChart(c *gin.Context) {
 		return
 	}
 	
+	s.importChart(c, req, 0)
+}
+
+// importChart fetches, checks and stores a chart through pkg.ImportChart and
+// answers with the outcome; attemptID is the parse attempt being retried, if any.
+func (s *Server) importChart(c *gin.Context, req pkg.ChartRequest, attemptID int32) {
 	log.Printf("🚀 Fetching chart: %s\n", req.ChartURL)
 	
 	chartUtils, err := charts.NewChartUtils(true)
@@ -48,37 +55,57 @@ func (s *Server) fetchChart(c *gin.Context) {
 		}
 	}
 	
-	chartInfo, apps, err := pkg.SafeParseChart(chartUtils, req)
-	if err != nil {
-		log.Printf("❌ Failed to fetch chart %s: %v\n", req.ChartURL, err)
-		c.JSON(http.StatusBadRequest, gin.H{
+	imported, err := pkg.ImportChart(s.db, chartUtils, req, attemptID)
+	var parseErr *pkg.ParseError
+	var validationErr *pkg.ValuesValidationError
+	var deniedErr *pkg.PolicyDeniedError
+	switch {
+	case errors.As(err, &parseErr):
+		response := gin.H{
 			"error": "Failed to fetch chart",
 			"details": err.Error(),
 			"chart_url": req.ChartURL,
+			"parse_error": parseErr,
+		}
+		if imported.AttemptID != 0 {
+			response["attempt_id"] = imported.AttemptID
+		}
+		status := http.StatusBadRequest
+		if parseErr.Stage == pkg.ParseStageStore && parseErr.Category == pkg.ParseErrorUnknown {
+			// Neither a missing nor an unauthorized dependency, the database failed
+			response["error"] = "Failed to store chart"
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, response)
+		return
+	case errors.As(err, &validationErr):
+		s.valuesError(c, err)
+		return
+	case errors.As(err, &deniedErr):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "Chart rejected by policy",
+			"chart_url": req.ChartURL,
+			"violations": deniedErr.Violations,
 		})
 		return
+	case err != nil:
+		log.Printf("❌ Failed to import chart %s: %v\n", req.ChartURL, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "chart_url": req.ChartURL})
+		return
 	}
-	
-	log.Printf("✅ Successfully parsed chart: %s v%s\n", chartInfo.Chart.Name, chartInfo.Chart.Version)
-	
-	storedChart, err := pkg.StoreChartInDB(s.db, chartInfo, apps, req.ChartURL)
-	if err != nil {
-		log.Printf("⚠️  Warning: failed to store chart in database: %v\n", err)
-		// Continue anyway, return the chart info
-	} else {
-		log.Printf("✅ Chart stored in database with ID: %d\n", storedChart.ID)
-	}
+	chartInfo, apps, storedChart := imported.ChartInfo, imported.Apps, imported.Stored
 	
 	response := map[string]interface{}{
 		"message": "Chart fetched successfully",
 		"chart": chartInfo,
 		"apps":  apps,
 		"dependencies_count": len(chartInfo.Chart.Dependencies),
+		"stored": true,
+		"chart_id": storedChart.ID,
 	}
 	
-	if storedChart != nil {
-		response["stored"] = true
-		response["chart_id"] = storedChart.ID
+	if imported.AttemptID != 0 {
+		response["attempt_id"] = imported.AttemptID
 	}
 	
 	// Add info about dependencies
@@ -102,6 +129,7 @@ func (s *Server) getStoredCharts(c *gin.Context) {
 	log.Printf("This should ONLY use database, NO directory scanning!\n")
 	
 	ctx := context.Background()
+	includeDisabled := includeDisabledDependencies(c)
 	
 	log.Printf("Testing database connection...\n")
 	if err := s.db.Ping(ctx); err != nil {
@@ -121,6 +149,11 @@ func (s *Server) getStoredCharts(c *gin.Context) {
 	
 	log.Printf("✅ Found %d charts in database\n", len(charts))
 	
+	vulnerabilities, err := pkg.LoadVulnerabilitySummaries(s.db, "", includeDisabled)
+	if err != nil {
+		log.Printf("Warning: failed to load vulnerability counts: %v\n", err)
+	}
+	
 	// Initialize as empty slice to ensure we always return an array
 	chartInfos := make([]pkg.ChartInfo, 0)
 	
@@ -134,9 +167,23 @@ func (s *Server) getStoredCharts(c *gin.Context) {
 			dependencies = []db.GetChartDependenciesRow{}
 		}
 		
+		// Summaries are scored when the chart is stored; rows stored before
+		// that are analyzed here instead
+		security := pkg.DecodeSecuritySummary(chart.SecuritySummary)
+		var posture *pkg.SecurityPosture
+		var rendered map[string]bool
+		if security == nil {
+			analyzed := pkg.AnalyzeSecurity(chart.Manifest.String)
+			posture, security = &analyzed, &analyzed.Summary
+			rendered = pkg.RenderedSubcharts(chart.Manifest.String)
+		}
+		
 		// Convert dependencies to Chart format
 		var chartDeps []pkg.Dependency
 		for _, dep := range dependencies {
+			if !dep.Enabled && !includeDisabled {
+				continue
+			}
 			var repo, cond string
 			if dep.Repository.Valid {
 				repo = dep.Repository.String
@@ -144,12 +191,27 @@ func (s *Server) getStoredCharts(c *gin.Context) {
 			if dep.ConditionField.Valid {
 				cond = dep.ConditionField.String
 			}
+			var tags []string
+			if dep.Tags.Valid {
+				json.Unmarshal([]byte(dep.Tags.String), &tags)
+			}
+			depSecurity := pkg.DecodeSecuritySummary(dep.SecuritySummary)
+			if posture != nil {
+				depSecurity = pkg.DependencySecurity(*posture, rendered, dep)
+			}
 			chartDeps = append(chartDeps, pkg.Dependency{
 				Name:       dep.DependencyName,
+				Alias:      dep.Alias,
 				Version:    dep.DependencyVersion,
 				Repository: repo,
 				Condition:  cond,
+				Tags:       tags,
+				Enabled:    dep.Enabled,
+				Security:   depSecurity,
 			})
+			if summary, ok := vulnerabilities[chart.ID]; ok {
+				chartDeps[len(chartDeps)-1].Vulnerabilities = summary.Dependencies[pkg.StoredDependencyKey(dep)]
+			}
 		}
 		
 		var desc string
@@ -174,6 +236,10 @@ func (s *Server) getStoredCharts(c *gin.Context) {
 		if chart.CanaryTag.Valid {
 			chartInfo.CanaryTag = chart.CanaryTag.String
 		}
+		if summary, ok := vulnerabilities[chart.ID]; ok {
+			chartInfo.Vulnerabilities = &summary.Counts
+		}
+		chartInfo.Security = security
 		
 		log.Printf("Chart %s has %d dependencies\n", chart.Name, len(chartDeps))
 		chartInfos = append(chartInfos, chartInfo)
@@ -195,6 +261,15 @@ func (s *Server) getStoredChartInfo(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Chart not found"})
 		return
 	}
+
+	render, ok := s.profileRender(c, chart)
+	if !ok {
+		return
+	}
+	if render != nil {
+		c.JSON(http.StatusOK, pkg.ChartInfoFromRender(chart, *render, c.Query("profile")))
+		return
+	}
 	
 	var desc string
 	if chart.Description.Valid {
@@ -207,8 +282,10 @@ func (s *Server) getStoredChartInfo(c *gin.Context) {
 			Description: desc,
 			Type:        chart.Type,
 		},
-		ImageTag:  "N/A",
-		CanaryTag: "N/A",
+		ImageTag:    "N/A",
+		CanaryTag:   "N/A",
+		KubeVersion: chart.KubeVersion.String,
+		APIVersions: pkg.DecodeStringList(chart.ApiVersions),
 	}
 	
 	if chart.ImageTag.Valid {
@@ -217,6 +294,19 @@ func (s *Server) getStoredChartInfo(c *gin.Context) {
 	if chart.CanaryTag.Valid {
 		chartInfo.CanaryTag = chart.CanaryTag.String
 	}
+	if vulnerabilities, err := pkg.LoadVulnerabilitySummaries(s.db, chart.Name, false); err == nil {
+		if summary, ok := vulnerabilities[chart.ID]; ok {
+			chartInfo.Vulnerabilities = &summary.Counts
+		}
+	}
+	if violations, err := pkg.LoadPolicyViolations(s.db, chart.ID); err == nil {
+		chartInfo.Violations = violations
+	}
+	chartInfo.Security = pkg.DecodeSecuritySummary(chart.SecuritySummary)
+	if chartInfo.Security == nil {
+		security := pkg.AnalyzeSecurity(chart.Manifest.String).Summary
+		chartInfo.Security = &security
+	}
 	
 	c.JSON(http.StatusOK, chartInfo)
 }
@@ -232,6 +322,13 @@ func (s *Server) deleteChart(c *gin.Context) {
 		return
 	}
 	
+	if err := queries.DeleteChartPin(ctx, chartName); err != nil {
+		log.Printf("⚠️  Warning: failed to remove pinned version of %s: %v\n", chartName, err)
+	}
+	if err := queries.DeleteChartSettings(ctx, chartName); err != nil {
+		log.Printf("⚠️  Warning: failed to remove settings of %s: %v\n", chartName, err)
+	}
+	
 	c.JSON(http.StatusOK, gin.H{"message": "Chart deleted successfully"})
 }
 
@@ -250,7 +347,20 @@ func (s *Server) deleteChartVersion(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Chart version deleted successfully"})
+	// A deleted pin no longer applies
+	if pin, err := queries.GetChartPin(ctx, chartName); err == nil && pin.Version == version {
+		queries.DeleteChartPin(ctx, chartName)
+	}
+	latest, err := pkg.RecomputeLatest(ctx, queries, chartName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Chart version deleted successfully",
+		"latest":  latest,
+	})
 }
 
 func (s *Server) getChartVersions(c *gin.Context) {
@@ -264,13 +374,38 @@ func (s *Server) getChartVersions(c *gin.Context) {
 		return
 	}
 	
+	pkg.SortChartVersions(versions)
+	var pinned string
+	if pin, err := queries.GetChartPin(ctx, chartName); err == nil {
+		pinned = pin.Version
+	}
+	var includePrereleases bool
+	if settings, err := queries.GetChartSettings(ctx, chartName); err == nil {
+		includePrereleases = settings.IncludePrereleases
+	}
+	
+	// Vulnerability counts keyed by version
+	counts := map[string]pkg.VulnerabilityCounts{}
+	if summaries, err := pkg.LoadVulnerabilitySummaries(s.db, chartName, false); err == nil {
+		for _, version := range versions {
+			if summary, ok := summaries[version.ID]; ok {
+				counts[version.Version] = summary.Counts
+			}
+		}
+	}
+	
 	c.JSON(http.StatusOK, gin.H{
 		"chart": chartName,
 		"versions": versions,
 		"count": len(versions),
+		"pinned": pinned,
+		"includePrereleases": includePrereleases,
+		"vulnerabilities": counts,
 	})
 }
 
+// switchChartVersion pins a version as latest regardless of semver
+// precedence, until it is unpinned or deleted.
 func (s *Server) switchChartVersion(c *gin.Context) {
 	ctx := context.Background()
 	chartName := c.Param("name")
@@ -279,31 +414,180 @@ func (s *Server) switchChartVersion(c *gin.Context) {
 	var req struct {
 		Version string `json:"version"`
 	}
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := c.ShouldBindJSON(&req); err != nil || req.Version == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
 		return
 	}
 	
-	err := queries.SetLatestVersion(ctx, chartName)
+	_, err := queries.GetChartVersion(ctx, db.GetChartVersionParams{
+		Name:    chartName,
+		Version: req.Version,
+	})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Version %s of chart %s not found", req.Version, chartName)})
+		return
+	}
+	
+	_, err = queries.UpsertChartPin(ctx, db.UpsertChartPinParams{
+		ChartName: chartName,
+		Version:   req.Version,
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	
-	// Set the requested version as latest
-	err = queries.SetVersionAsLatest(ctx, db.SetVersionAsLatestParams{
-		Name:    chartName,
-		Version: req.Version,
+	latest, err := pkg.RecomputeLatest(ctx, queries, chartName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Pinned %s to version %s", chartName, latest),
+		"chart": chartName,
+		"version": latest,
+		"pinned": true,
 	})
+}
+
+// unpinChartVersion drops a pinned version so latest follows semver again.
+func (s *Server) unpinChartVersion(c *gin.Context) {
+	ctx := context.Background()
+	chartName := c.Param("name")
+	queries := db.New(s.db)
+	
+	if err := queries.DeleteChartPin(ctx, chartName); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	
+	latest, err := pkg.RecomputeLatest(ctx, queries, chartName)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	
 	c.JSON(http.StatusOK, gin.H{
-		"message": fmt.Sprintf("Switched %s to version %s", chartName, req.Version),
+		"message": fmt.Sprintf("Unpinned %s, latest is %s", chartName, latest),
 		"chart": chartName,
-		"version": req.Version,
+		"version": latest,
+		"pinned": false,
 	})
 }
 
+
+// updateChartSettings changes per-chart settings. Opting into prereleases lets
+// the highest version become latest even when it is a prerelease.
+func (s *Server) updateChartSettings(c *gin.Context) {
+	ctx := context.Background()
+	chartName := c.Param("name")
+	queries := db.New(s.db)
+
+	var req struct {
+		IncludePrereleases bool `json:"includePrereleases"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	if _, err := queries.GetChart(ctx, chartName); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chart not found"})
+		return
+	}
+
+	_, err := queries.UpsertChartSettings(ctx, db.UpsertChartSettingsParams{
+		ChartName:          chartName,
+		IncludePrereleases: req.IncludePrereleases,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	latest, err := pkg.RecomputeLatest(ctx, queries, chartName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"chart":              chartName,
+		"includePrereleases": req.IncludePrereleases,
+		"latest":             latest,
+	})
+}
+
+// backfillChartHistory imports published versions of a chart that were never
+// fetched, the last N by default or those matching a semver range.
+func (s *Server) backfillChartHistory(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+	chartName := c.Param("name")
+
+	var req struct {
+		Limit              int    `json:"limit"`
+		Range              string `json:"range"`
+		IncludePrereleases bool   `json:"includePrereleases"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+			return
+		}
+	}
+	if req.Limit == 0 && req.Range == "" {
+		req.Limit = 10
+	}
+
+	chart, err := queries.GetChart(ctx, chartName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chart not found"})
+		return
+	}
+
+	log.Printf("📜 Backfilling history of %s from %s\n", chartName, chart.ChartUrl)
+	result, err := pkg.BackfillChartHistory(s.db, chart, req.Range, req.Limit, req.IncludePrereleases)
+	if err != nil {
+		log.Printf("❌ Failed to backfill history of %s: %v\n", chartName, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to backfill chart history", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+
+// whatIfDependencyBump dry-runs bumping one dependency of the latest stored
+// version of a chart. The stored chart is left untouched.
+func (s *Server) whatIfDependencyBump(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+	chartName := c.Param("name")
+
+	var req struct {
+		Dependency string `json:"dependency"`
+		Version    string `json:"version"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Dependency == "" || req.Version == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dependency and version are required"})
+		return
+	}
+
+	chart, err := queries.GetChart(ctx, chartName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chart not found"})
+		return
+	}
+
+	log.Printf("🔮 What if %s bumps %s to %s\n", chartName, req.Dependency, req.Version)
+	result, err := pkg.AnalyzeDependencyBump(s.db, chart, req.Dependency, req.Version)
+	if err != nil {
+		log.Printf("❌ What-if for %s failed: %v\n", chartName, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to analyze dependency bump", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"chartpaper/internal/db"
+	"chartpaper/pkg"
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) compareProfiles(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+
+	var profiles []string
+	for _, profile := range strings.Split(c.Query("profiles"), ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			profiles = append(profiles, profile)
+		}
+	}
+	if len(profiles) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "profiles query parameter is required, e.g. ?profiles=staging,prod"})
+		return
+	}
+	chartFilter := c.Query("chart")
+
+	charts, err := queries.ListCharts(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	comparison := pkg.Comparison{Profiles: profiles, Charts: []pkg.ChartComparison{}}
+	for _, chart := range charts {
+		if chartFilter != "" && chart.Name != chartFilter {
+			continue
+		}
+
+		snapshots := map[string]pkg.ProfileSnapshot{}
+		for _, profileName := range profiles {
+			profile, err := queries.GetValuesProfile(ctx, db.GetValuesProfileParams{ChartName: chart.Name, Name: profileName})
+			if err != nil {
+				continue
+			}
+			render, err := queries.GetDeployedProfileRender(ctx, db.GetDeployedProfileRenderParams{Name: chart.Name, ProfileID: profile.ID})
+			if err != nil {
+				continue
+			}
+			snapshots[profileName] = pkg.ProfileSnapshot{Version: render.ChartVersion, Manifest: render.Manifest.String}
+		}
+		if len(snapshots) == 0 {
+			continue
+		}
+
+		chartComparison := pkg.CompareChart(chart.Name, profiles, snapshots)
+		if chartComparison.Drift {
+			comparison.DriftCount++
+		}
+		comparison.Charts = append(comparison.Charts, chartComparison)
+	}
+
+	c.JSON(http.StatusOK, comparison)
+}
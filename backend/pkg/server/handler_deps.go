@@ -6,6 +6,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/ashupednekar/compose/pkg/spec"
 	"github.com/gin-gonic/gin"
@@ -50,7 +51,7 @@ func (s *Server) fetchChartDependencies(c *gin.Context) {
 			}
 
 			for _, registryURL := range registries {
-				chartInfo, err := pkg.TryFetchChart(s.db, registryURL, dep.DependencyName, dep.DependencyVersion)
+				chartInfo, err := pkg.TryFetchChart(s.db, registryURL, dep.DependencyName, dep.DependencyVersion, chart.KubeVersion.String, pkg.DecodeStringList(chart.ApiVersions))
 				if err == nil {
 
 					_, storeErr := pkg.StoreChartInDB(s.db, *chartInfo, []spec.App{}, registryURL)
@@ -95,6 +96,27 @@ func (s *Server) getChartDependencies(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+
+	render, ok := s.profileRender(c, chart)
+	if !ok {
+		return
+	}
+	if render != nil {
+		disabled := pkg.DisabledDependencies(*render)
+		for i := range dependencies {
+			dependencies[i].Enabled = !disabled[pkg.StoredDependencyKey(dependencies[i])]
+		}
+	}
+
+	if !includeDisabledDependencies(c) {
+		enabled := dependencies[:0]
+		for _, dep := range dependencies {
+			if dep.Enabled {
+				enabled = append(enabled, dep)
+			}
+		}
+		dependencies = enabled
+	}
 	
 	if len(dependencies) == 0 {
 		fmt.Printf("ℹ️  Chart %s has no dependencies\n", chartName)
@@ -107,10 +129,44 @@ func (s *Server) getChartDependencies(c *gin.Context) {
 		return
 	}
 	
+	// Vulnerability counts keyed by dependency alias or name
+	counts := map[string]*pkg.VulnerabilityCounts{}
+	summaries, err := pkg.LoadVulnerabilitySummaries(s.db, chartName, includeDisabledDependencies(c))
+	if err == nil && summaries[chart.ID] != nil {
+		for _, dep := range dependencies {
+			if depCounts, ok := summaries[chart.ID].Dependencies[pkg.StoredDependencyKey(dep)]; ok {
+				counts[pkg.StoredDependencyKey(dep)] = depCounts
+			}
+		}
+	}
+	
+	// Security summaries keyed by dependency alias or name, from the profile's render if any
+	manifest := chart.Manifest.String
+	if render != nil {
+		manifest = render.Manifest.String
+	}
+	posture := pkg.AnalyzeSecurity(manifest)
+	rendered := pkg.RenderedSubcharts(manifest)
+	security := map[string]*pkg.SecuritySummary{}
+	for _, dep := range dependencies {
+		if summary := pkg.DependencySecurity(posture, rendered, dep); summary != nil {
+			security[pkg.StoredDependencyKey(dep)] = summary
+		}
+	}
+	
 	fmt.Printf("✅ Found %d dependencies for chart %s\n", len(dependencies), chartName)
 	c.JSON(http.StatusOK, gin.H{
 		"chart": chartName,
 		"dependencies": dependencies,
 		"count": len(dependencies),
+		"vulnerabilities": counts,
+		"security": security,
 	})
 }
+
+// includeDisabledDependencies reports whether the caller asked for dependency
+// edges whose condition or tags evaluated to disabled.
+func includeDisabledDependencies(c *gin.Context) bool {
+	include, _ := strconv.ParseBool(c.Query("includeDisabled"))
+	return include
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"chartpaper/internal/db"
+	"chartpaper/pkg"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (s *Server) getImages(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+
+	allVersions, _ := strconv.ParseBool(c.Query("allVersions"))
+	images, err := queries.ListImages(ctx, db.ListImagesParams{
+		Repository:      queryText(c, "repo"),
+		Tag:             queryText(c, "tag"),
+		ChartName:       queryText(c, "chart"),
+		Profile:         queryText(c, "profile"),
+		IncludeDisabled: includeDisabledDependencies(c),
+		AllVersions:     allVersions,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if images == nil {
+		images = []db.ListImagesRow{}
+	}
+
+	charts := []string{}
+	seen := map[string]bool{}
+	for _, image := range images {
+		if !seen[image.ChartName] {
+			seen[image.ChartName] = true
+			charts = append(charts, image.ChartName)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"images": images,
+		"count":  len(images),
+		"charts": charts,
+	})
+}
+
+// resolveImageDigests looks up the current digest of every stored image tag,
+// optionally limited to ?chart=, and reports tags that moved since the last sync.
+func (s *Server) resolveImageDigests(c *gin.Context) {
+	digests, err := pkg.ResolveImageDigests(s.db, c.Query("chart"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	moved := 0
+	failed := 0
+	for _, digest := range digests {
+		if digest.DigestChanged {
+			moved++
+		}
+		if digest.Error != "" {
+			failed++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"images": digests,
+		"count":  len(digests),
+		"moved":  moved,
+		"failed": failed,
+	})
+}
+
+// uploadScanResults stores a Trivy or Grype JSON report. The report is keyed by
+// the image it names, or by ?image= which may be a reference or a digest.
+func (s *Server) uploadScanResults(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	report, err := pkg.ParseScanReport(body, c.Query("image"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := pkg.StoreScanReport(s.db, report); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	log.Printf("🛡️  Stored %d %s findings for %s\n", len(report.Vulnerabilities), report.Scanner, report.Image)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Scan results stored successfully",
+		"report":  report,
+	})
+}
+
+// queryText maps an optional query parameter to a nullable SQL argument.
+func queryText(c *gin.Context, key string) pgtype.Text {
+	value := c.Query(key)
+	return pgtype.Text{String: value, Valid: value != ""}
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"chartpaper/internal/db"
+	"chartpaper/pkg"
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (s *Server) getParseAttempts(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+
+	// Failed imports are what needs triage, so they are the default
+	status := c.DefaultQuery("status", pkg.ParseAttemptFailed)
+	if status == "all" {
+		status = ""
+	}
+	category := c.Query("category")
+	chartName := c.Query("chart")
+
+	rows, err := queries.ListParseAttempts(ctx, db.ListParseAttemptsParams{
+		Status:    pgtype.Text{String: status, Valid: status != ""},
+		Category:  pgtype.Text{String: category, Valid: category != ""},
+		ChartName: pgtype.Text{String: chartName, Valid: chartName != ""},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	attempts := make([]pkg.ParseAttempt, 0, len(rows))
+	for _, row := range rows {
+		attempts = append(attempts, pkg.ParseAttemptFromDB(row))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"attempts": attempts,
+		"count":    len(attempts),
+	})
+}
+
+func (s *Server) getParseAttempt(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parse attempt ID"})
+		return
+	}
+
+	attempt, err := queries.GetParseAttempt(ctx, int32(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Parse attempt not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pkg.ParseAttemptFromDB(attempt))
+}
+
+// retryParseAttempt imports the chart again with the recorded inputs. The
+// attempt is resolved on success or updated with the new error.
+func (s *Server) retryParseAttempt(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parse attempt ID"})
+		return
+	}
+
+	attempt, err := queries.GetParseAttempt(ctx, int32(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Parse attempt not found"})
+		return
+	}
+	if attempt.Status == pkg.ParseAttemptResolved {
+		c.JSON(http.StatusConflict, gin.H{"error": "Parse attempt is already resolved"})
+		return
+	}
+
+	s.importChart(c, pkg.ParseAttemptFromDB(attempt).Request, attempt.ID)
+}
+
+func (s *Server) deleteParseAttempt(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parse attempt ID"})
+		return
+	}
+
+	if err := queries.DeleteParseAttempt(ctx, int32(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Parse attempt deleted successfully"})
+}
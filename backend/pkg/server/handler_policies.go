@@ -0,0 +1,80 @@
+package server
+
+import (
+	"chartpaper/internal/db"
+	"chartpaper/pkg"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (s *Server) getPolicies(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+
+	rows, err := queries.ListPolicies(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	policies := make([]pkg.Policy, 0, len(rows))
+	for _, row := range rows {
+		policies = append(policies, pkg.PolicyFromDB(row))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"policies": policies,
+		"count":    len(policies),
+	})
+}
+
+func (s *Server) savePolicy(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+
+	req := pkg.Policy{Scope: pkg.PolicyScopeObject, Action: pkg.PolicyActionWarn, Enabled: true}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+	req.Name = c.Param("name")
+	if err := pkg.ValidatePolicy(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy, err := queries.UpsertPolicy(ctx, db.UpsertPolicyParams{
+		Name:        req.Name,
+		Description: pgtype.Text{String: req.Description, Valid: req.Description != ""},
+		Expression:  req.Expression,
+		Scope:       req.Scope,
+		Message:     pgtype.Text{String: req.Message, Valid: req.Message != ""},
+		Action:      req.Action,
+		Enabled:     req.Enabled,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Saved policy %s", policy.Name),
+		"policy":  pkg.PolicyFromDB(policy),
+	})
+}
+
+func (s *Server) deletePolicy(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+
+	if err := queries.DeletePolicy(ctx, c.Param("name")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Policy deleted successfully"})
+}
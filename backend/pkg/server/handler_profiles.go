@@ -0,0 +1,232 @@
+package server
+
+import (
+	"chartpaper/internal/db"
+	"chartpaper/pkg"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (s *Server) getValuesProfiles(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+	chartName := c.Param("name")
+
+	rows, err := queries.ListValuesProfiles(ctx, chartName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	profiles := make([]pkg.ValuesProfile, 0, len(rows))
+	for _, row := range rows {
+		profiles = append(profiles, pkg.ProfileFromDB(row))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"chart":    chartName,
+		"profiles": profiles,
+		"count":    len(profiles),
+	})
+}
+
+func (s *Server) saveValuesProfile(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+	chartName := c.Param("name")
+	profileName := c.Param("profile")
+
+	var req pkg.ValuesProfile
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	// Reject values the schema of the deployed version, or else the latest
+	// stored one, would fail on
+	var chart db.Chart
+	var chartErr error
+	if req.ChartVersion != "" {
+		chart, chartErr = queries.GetChartVersion(ctx, db.GetChartVersionParams{Name: chartName, Version: req.ChartVersion})
+	} else {
+		chart, chartErr = queries.GetChart(ctx, chartName)
+	}
+	if chartErr == nil {
+		if err := pkg.ValidateChartValues(s.db, chart.ID, req.ValuesFiles, req.SetValues); err != nil {
+			s.valuesError(c, err)
+			return
+		}
+	}
+
+	valuesFilesJSON, _ := json.Marshal(req.ValuesFiles)
+	setValuesJSON, _ := json.Marshal(req.SetValues)
+	apiVersionsJSON, _ := json.Marshal(req.APIVersions)
+	profile, err := queries.UpsertValuesProfile(ctx, db.UpsertValuesProfileParams{
+		ChartName:      chartName,
+		Name:           profileName,
+		ValuesFiles:    pgtype.Text{String: string(valuesFilesJSON), Valid: len(req.ValuesFiles) > 0},
+		SetValues:      pgtype.Text{String: string(setValuesJSON), Valid: len(req.SetValues) > 0},
+		UseHostNetwork: pgtype.Bool{Bool: req.UseHostNetwork, Valid: true},
+		KubeVersion:    pgtype.Text{String: req.KubeVersion, Valid: req.KubeVersion != ""},
+		ApiVersions:    pgtype.Text{String: string(apiVersionsJSON), Valid: len(req.APIVersions) > 0},
+		ChartVersion:   pgtype.Text{String: req.ChartVersion, Valid: req.ChartVersion != ""},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{
+		"message":  fmt.Sprintf("Saved profile %s for %s", profileName, chartName),
+		"profile":  pkg.ProfileFromDB(profile),
+		"rendered": false,
+	}
+
+	if chartErr != nil {
+		response["info"] = "Chart version not fetched yet, profile will be rendered when it is"
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	render, err := pkg.RenderChartProfile(s.db, chart, profile)
+	if err != nil {
+		log.Printf("⚠️  Warning: %v\n", err)
+		response["error"] = err.Error()
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	response["rendered"] = true
+	response["chart"] = pkg.ChartInfoFromRender(chart, *render, profile.Name)
+	c.JSON(http.StatusOK, response)
+}
+
+func (s *Server) renderValuesProfile(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+	chartName := c.Param("name")
+	profileName := c.Param("profile")
+
+	profile, err := queries.GetValuesProfile(ctx, db.GetValuesProfileParams{ChartName: chartName, Name: profileName})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found", "profile": profileName})
+		return
+	}
+
+	// Render the version the environment deploys unless another stored one is asked for
+	chart, ok := s.chartVersionOr(c, profile.ChartVersion.String)
+	if !ok {
+		return
+	}
+
+	render, err := pkg.RenderChartProfile(s.db, chart, profile)
+	if err != nil {
+		var validationErr *pkg.ValuesValidationError
+		if errors.As(err, &validationErr) {
+			s.valuesError(c, err)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to render profile", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pkg.ChartInfoFromRender(chart, *render, profile.Name))
+}
+
+func (s *Server) deleteValuesProfile(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+
+	err := queries.DeleteValuesProfile(ctx, db.DeleteValuesProfileParams{
+		ChartName: c.Param("name"),
+		Name:      c.Param("profile"),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Profile deleted successfully"})
+}
+
+// chartVersion loads the chart named by the :name path parameter at the
+// ?version= query parameter, or its latest version when none is given. It
+// writes a 404 when that version isn't stored.
+func (s *Server) chartVersion(c *gin.Context) (db.Chart, bool) {
+	return s.chartVersionOr(c, "")
+}
+
+// chartVersionOr is chartVersion with the version to load when ?version= is
+// not given, the latest when fallback is empty too.
+func (s *Server) chartVersionOr(c *gin.Context, fallback string) (db.Chart, bool) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+	chartName := c.Param("name")
+
+	version := c.Query("version")
+	if version == "" {
+		version = fallback
+	}
+	var chart db.Chart
+	var err error
+	if version != "" {
+		chart, err = queries.GetChartVersion(ctx, db.GetChartVersionParams{Name: chartName, Version: version})
+	} else {
+		chart, err = queries.GetChart(ctx, chartName)
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chart not found"})
+		return chart, false
+	}
+	return chart, true
+}
+
+// profileRender loads the render of chart for the ?profile= query parameter.
+// It returns nil without writing a response when no profile was requested,
+// and writes a 404 when the profile or its render is missing.
+func (s *Server) profileRender(c *gin.Context, chart db.Chart) (*db.ChartRender, bool) {
+	profileName := c.Query("profile")
+	if profileName == "" {
+		return nil, true
+	}
+
+	queries := db.New(s.db)
+	ctx := context.Background()
+	profile, err := queries.GetValuesProfile(ctx, db.GetValuesProfileParams{ChartName: chart.Name, Name: profileName})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found", "profile": profileName})
+		return nil, false
+	}
+
+	render, err := queries.GetChartRender(ctx, db.GetChartRenderParams{ChartID: chart.ID, ProfileID: profile.ID})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Profile has not been rendered for this chart version",
+			"profile": profileName,
+			"version": chart.Version,
+		})
+		return nil, false
+	}
+	return &render, true
+}
+
+// valuesError writes a 422 listing the fields a values.schema.json rejected,
+// or a 400 when the values could not be read at all.
+func (s *Server) valuesError(c *gin.Context, err error) {
+	var validationErr *pkg.ValuesValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "Values do not match the chart's values.schema.json",
+			"fields": validationErr.Errors,
+		})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid values", "details": err.Error()})
+}
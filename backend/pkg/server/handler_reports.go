@@ -0,0 +1,141 @@
+package server
+
+import (
+	"chartpaper/internal/db"
+	"chartpaper/pkg"
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type chartDeprecations struct {
+	Chart    string                   `json:"chart"`
+	Version  string                   `json:"version"`
+	IsLatest bool                     `json:"isLatest"`
+	Findings []pkg.DeprecationFinding `json:"findings"`
+	Removed  int                      `json:"removed"`
+}
+
+// getDeprecationReport lists the rendered resources of stored charts that use
+// APIs deprecated or removed in ?kubeVersion=. Only latest versions are checked
+// unless ?allVersions=true; ?chart= narrows the report to one chart.
+func (s *Server) getDeprecationReport(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+
+	kubeVersion, err := pkg.ParseKubeVersion(c.Query("kubeVersion"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	allVersions, _ := strconv.ParseBool(c.Query("allVersions"))
+	var charts []db.Chart
+	if allVersions {
+		charts, err = queries.ListAllChartVersions(ctx)
+	} else {
+		charts, err = queries.ListCharts(ctx)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	chartFilter := c.Query("chart")
+	reports := []chartDeprecations{}
+	checked, removed, deprecated := 0, 0, 0
+	for _, chart := range charts {
+		if chartFilter != "" && chart.Name != chartFilter {
+			continue
+		}
+		checked++
+		findings := pkg.FindDeprecatedAPIs(chart.Manifest.String, kubeVersion)
+		if len(findings) == 0 {
+			continue
+		}
+		report := chartDeprecations{
+			Chart:    chart.Name,
+			Version:  chart.Version,
+			IsLatest: chart.IsLatest.Bool,
+			Findings: findings,
+		}
+		for _, finding := range findings {
+			if finding.Removed {
+				report.Removed++
+				removed++
+			} else {
+				deprecated++
+			}
+		}
+		reports = append(reports, report)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"kubeVersion": kubeVersion.String(),
+		"charts":      reports,
+		"checked":     checked,
+		"removed":     removed,
+		"deprecated":  deprecated,
+	})
+}
+
+// getCapacityReport totals the resource footprint of every stored chart's
+// latest version, once with default values and once per rendered profile
+// unless ?profiles=false. ?chart= narrows the report to one chart.
+func (s *Server) getCapacityReport(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+
+	charts, err := queries.ListCharts(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	includeProfiles := true
+	if value := c.Query("profiles"); value != "" {
+		includeProfiles, _ = strconv.ParseBool(value)
+	}
+
+	chartFilter := c.Query("chart")
+	reports := []pkg.ChartCapacity{}
+	for _, chart := range charts {
+		if chartFilter != "" && chart.Name != chartFilter {
+			continue
+		}
+		capacity, err := pkg.ChartVersionCapacity(ctx, queries, chart, "", chart.Manifest.String, nil)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		reports = append(reports, capacity)
+
+		if !includeProfiles {
+			continue
+		}
+		profiles, err := queries.ListValuesProfiles(ctx, chart.Name)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		for _, profile := range profiles {
+			render, err := queries.GetChartRender(ctx, db.GetChartRenderParams{ChartID: chart.ID, ProfileID: profile.ID})
+			if err != nil {
+				continue
+			}
+			capacity, err := pkg.ChartVersionCapacity(ctx, queries, chart, profile.Name, render.Manifest.String, pkg.DisabledDependencies(render))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			reports = append(reports, capacity)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"charts": reports,
+		"count":  len(reports),
+	})
+}
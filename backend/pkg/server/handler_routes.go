@@ -0,0 +1,57 @@
+package server
+
+import (
+	"chartpaper/internal/db"
+	"chartpaper/pkg"
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getRoutes returns the route table of every stored chart's latest version,
+// including profile renders unless ?profiles=false, and the host and path
+// pairs claimed by more than one chart. ?host= and ?chart= narrow the table,
+// conflicts are always computed across all charts.
+func (s *Server) getRoutes(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+
+	charts, err := queries.ListCharts(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	includeProfiles := true
+	if value := c.Query("profiles"); value != "" {
+		includeProfiles, _ = strconv.ParseBool(value)
+	}
+	routes, err := pkg.BuildRouteTable(ctx, queries, charts, includeProfiles)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	conflicts := pkg.FindRouteConflicts(routes)
+
+	hostFilter := strings.ToLower(c.Query("host"))
+	chartFilter := c.Query("chart")
+	filtered := []pkg.Route{}
+	for _, route := range routes {
+		if hostFilter != "" && strings.ToLower(route.Host) != hostFilter {
+			continue
+		}
+		if chartFilter != "" && route.Chart != chartFilter {
+			continue
+		}
+		filtered = append(filtered, route)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"routes":    filtered,
+		"count":     len(filtered),
+		"conflicts": conflicts,
+	})
+}
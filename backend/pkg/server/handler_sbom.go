@@ -0,0 +1,43 @@
+package server
+
+import (
+	"chartpaper/internal/db"
+	"chartpaper/pkg"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getChartSBOM exports the chart, its subcharts and their container images as
+// a CycloneDX (default) or SPDX JSON document. ?version= selects a stored version.
+func (s *Server) getChartSBOM(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+
+	format := c.DefaultQuery("format", "cyclonedx")
+	if format != "cyclonedx" && format != "spdx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be cyclonedx or spdx"})
+		return
+	}
+
+	chart, ok := s.chartVersion(c)
+	if !ok {
+		return
+	}
+
+	tree, err := pkg.BuildSBOMTree(ctx, queries, chart, includeDisabledDependencies(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s.json", chart.Name, chart.Version, format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if format == "spdx" {
+		c.JSON(http.StatusOK, tree.SPDX())
+		return
+	}
+	c.JSON(http.StatusOK, tree.CycloneDX())
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"chartpaper/pkg"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getChartSecurity returns the RBAC and pod security posture of a chart
+// version, the latest unless ?version= is given, rendered with ?profile= when
+// given. Findings list the subchart they come from.
+func (s *Server) getChartSecurity(c *gin.Context) {
+	chart, ok := s.chartVersion(c)
+	if !ok {
+		return
+	}
+
+	render, ok := s.profileRender(c, chart)
+	if !ok {
+		return
+	}
+	manifest := chart.Manifest.String
+	if render != nil {
+		manifest = render.Manifest.String
+	}
+
+	posture := pkg.AnalyzeSecurity(manifest)
+	posture.Chart = chart.Name
+	posture.Version = chart.Version
+	posture.Profile = c.Query("profile")
+	c.JSON(http.StatusOK, posture)
+}
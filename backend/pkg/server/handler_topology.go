@@ -0,0 +1,35 @@
+package server
+
+import (
+	"chartpaper/pkg"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getChartTopology returns the runtime topology of a chart version's rendered
+// manifest, rendered with ?profile= when given.
+func (s *Server) getChartTopology(c *gin.Context) {
+	chart, ok := s.chartVersion(c)
+	if !ok {
+		return
+	}
+
+	render, ok := s.profileRender(c, chart)
+	if !ok {
+		return
+	}
+	manifest := chart.Manifest.String
+	if render != nil {
+		manifest = render.Manifest.String
+	}
+
+	nodes, edges := pkg.BuildTopology(manifest)
+	c.JSON(http.StatusOK, pkg.Topology{
+		Chart:   chart.Name,
+		Version: chart.Version,
+		Profile: c.Query("profile"),
+		Nodes:   nodes,
+		Edges:   edges,
+	})
+}
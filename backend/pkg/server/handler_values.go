@@ -0,0 +1,183 @@
+package server
+
+import (
+	"chartpaper/internal/db"
+	"chartpaper/pkg"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// getValuesSchema returns the values.schema.json of a stored chart version so
+// clients can build a typed values editor.
+func (s *Server) getValuesSchema(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+	chartName := c.Param("name")
+	version := c.Param("version")
+
+	chart, err := queries.GetChartVersion(ctx, db.GetChartVersionParams{Name: chartName, Version: version})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chart version not found"})
+		return
+	}
+
+	chartValues, err := queries.GetChartValues(ctx, chart.ID)
+	if err != nil || !chartValues.ValuesSchema.Valid {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Chart version has no values.schema.json",
+			"chart":   chartName,
+			"version": version,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"chart":   chartName,
+		"version": version,
+		"schema":  json.RawMessage(chartValues.ValuesSchema.String),
+	})
+}
+
+// getValuesDocs lists the documented values of a stored chart version, as
+// JSON or with ?format=markdown as a helm-docs style table.
+func (s *Server) getValuesDocs(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+	chartName := c.Param("name")
+	version := c.Param("version")
+
+	chart, err := queries.GetChartVersion(ctx, db.GetChartVersionParams{Name: chartName, Version: version})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chart version not found"})
+		return
+	}
+
+	chartValues, err := queries.GetChartValues(ctx, chart.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No values stored for this chart version, fetch it again"})
+		return
+	}
+
+	// Versions stored before values.yaml was kept only have the decoded
+	// defaults, which still give keys, types and defaults without comments
+	valuesYAML := chartValues.ValuesYaml.String
+	if !chartValues.ValuesYaml.Valid && chartValues.DefaultValues.Valid {
+		var defaults map[string]interface{}
+		json.Unmarshal([]byte(chartValues.DefaultValues.String), &defaults)
+		data, _ := yaml.Marshal(defaults)
+		valuesYAML = string(data)
+	}
+
+	docs, err := pkg.ParseValuesDocs(valuesYAML)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.Query("format") == "markdown" {
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(pkg.ValuesDocsMarkdown(chartName, version, docs)))
+		return
+	}
+
+	documented := 0
+	for _, doc := range docs {
+		if doc.Description != "" {
+			documented++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"chart":      chartName,
+		"version":    version,
+		"values":     docs,
+		"count":      len(docs),
+		"documented": documented,
+	})
+}
+
+// getValuesDiff compares the default values of two stored versions and flags
+// profile overrides of keys the newer version dropped.
+func (s *Server) getValuesDiff(c *gin.Context) {
+	queries := db.New(s.db)
+	ctx := context.Background()
+	chartName := c.Param("name")
+	fromVersion := c.Query("from")
+	toVersion := c.Query("to")
+	if fromVersion == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from query parameter is required, e.g. ?from=1.2.0&to=1.3.0"})
+		return
+	}
+	if toVersion == "" {
+		latest, err := queries.GetChart(ctx, chartName)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Chart not found"})
+			return
+		}
+		toVersion = latest.Version
+	}
+
+	defaults := map[string]map[string]interface{}{}
+	for _, version := range []string{fromVersion, toVersion} {
+		chart, err := queries.GetChartVersion(ctx, db.GetChartVersionParams{Name: chartName, Version: version})
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Chart version not found", "version": version})
+			return
+		}
+		chartValues, err := queries.GetChartValues(ctx, chart.ID)
+		if err != nil || !chartValues.DefaultValues.Valid {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No default values stored for this chart version, fetch it again", "version": version})
+			return
+		}
+		values, err := pkg.DecodeDefaultValues(chartValues.DefaultValues.String)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		defaults[version] = values
+	}
+
+	changes := pkg.DiffValues(defaults[fromVersion], defaults[toVersion])
+	summary := map[string]int{
+		pkg.ValuesKeyAdded:          0,
+		pkg.ValuesKeyRemoved:        0,
+		pkg.ValuesKeyChangedDefault: 0,
+		pkg.ValuesKeyTypeChanged:    0,
+	}
+	for _, change := range changes {
+		summary[change.Change]++
+	}
+
+	profiles, err := queries.ListValuesProfiles(ctx, chartName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	staleOverrides := []pkg.StaleOverride{}
+	var warnings []string
+	for _, row := range profiles {
+		profile := pkg.ProfileFromDB(row)
+		overrides, err := pkg.UserValues(profile.ValuesFiles, profile.SetValues)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("profile %s: %v", profile.Name, err))
+			continue
+		}
+		staleOverrides = append(staleOverrides, pkg.StaleOverrides(profile.Name, overrides, defaults[fromVersion], defaults[toVersion])...)
+	}
+
+	response := gin.H{
+		"chart":          chartName,
+		"from":           fromVersion,
+		"to":             toVersion,
+		"changes":        changes,
+		"summary":        summary,
+		"staleOverrides": staleOverrides,
+	}
+	if len(warnings) > 0 {
+		response["warnings"] = warnings
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -9,11 +9,42 @@ func (s *Server) buildRoutes() error{
 		api.GET("/charts", s.getStoredCharts)
 		api.GET("/charts/:name", s.getStoredChartInfo)
 		api.GET("/charts/:name/versions", s.getChartVersions)
+		api.GET("/charts/:name/values-diff", s.getValuesDiff)
 		api.GET("/charts/:name/dependencies", s.getChartDependencies)
+		api.GET("/charts/:name/apps", s.getChartApps)
+		api.GET("/charts/:name/apps/:app", s.getChartApp)
+		api.GET("/charts/:name/compose.yaml", s.getComposeFile)
+		api.GET("/charts/:name/topology", s.getChartTopology)
+		api.GET("/charts/:name/security", s.getChartSecurity)
+		api.GET("/charts/:name/sbom", s.getChartSBOM)
+		api.GET("/charts/:name/versions/:version/values-schema", s.getValuesSchema)
+		api.GET("/charts/:name/versions/:version/values-docs", s.getValuesDocs)
 		api.POST("/charts/:name/fetch-dependencies", s.fetchChartDependencies)
 		api.POST("/charts/:name/switch-version", s.switchChartVersion)
+		api.DELETE("/charts/:name/pin", s.unpinChartVersion)
+		api.PUT("/charts/:name/settings", s.updateChartSettings)
+		api.POST("/charts/:name/backfill-history", s.backfillChartHistory)
+		api.POST("/charts/:name/what-if", s.whatIfDependencyBump)
+		api.GET("/charts/:name/profiles", s.getValuesProfiles)
+		api.PUT("/charts/:name/profiles/:profile", s.saveValuesProfile)
+		api.POST("/charts/:name/profiles/:profile/render", s.renderValuesProfile)
+		api.DELETE("/charts/:name/profiles/:profile", s.deleteValuesProfile)
+		api.GET("/compare", s.compareProfiles)
+		api.GET("/reports/deprecations", s.getDeprecationReport)
+		api.GET("/reports/capacity", s.getCapacityReport)
+		api.GET("/routes", s.getRoutes)
+		api.GET("/images", s.getImages)
+		api.POST("/images/resolve", s.resolveImageDigests)
+		api.POST("/images/scan-results", s.uploadScanResults)
+		api.GET("/policies", s.getPolicies)
+		api.PUT("/policies/:name", s.savePolicy)
+		api.DELETE("/policies/:name", s.deletePolicy)
 		api.GET("/docker-config", s.getDockerConfig)
 		api.POST("/fetch-chart", s.fetchChart)
+		api.GET("/parse-attempts", s.getParseAttempts)
+		api.GET("/parse-attempts/:id", s.getParseAttempt)
+		api.POST("/parse-attempts/:id/retry", s.retryParseAttempt)
+		api.DELETE("/parse-attempts/:id", s.deleteParseAttempt)
 		api.POST("/authenticate", s.authenticate)
 		api.DELETE("/charts/:name", s.deleteChart)
 		api.DELETE("/charts/:name/versions/:version", s.deleteChartVersion)
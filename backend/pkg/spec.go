@@ -1,5 +1,6 @@
 package pkg
 
+import "time"
 
 type Chart struct {
 	APIVersion   string       `yaml:"apiVersion" json:"apiVersion"`
@@ -11,10 +12,15 @@ type Chart struct {
 }
 
 type Dependency struct {
-	Name       string `yaml:"name" json:"name"`
-	Version    string `yaml:"version" json:"version"`
-	Repository string `yaml:"repository" json:"repository"`
-	Condition  string `yaml:"condition,omitempty" json:"condition,omitempty"`
+	Name       string   `yaml:"name" json:"name"`
+	Alias      string   `yaml:"alias,omitempty" json:"alias,omitempty"`
+	Version    string   `yaml:"version" json:"version"`
+	Repository string   `yaml:"repository" json:"repository"`
+	Condition  string   `yaml:"condition,omitempty" json:"condition,omitempty"`
+	Tags       []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Enabled    bool     `yaml:"-" json:"enabled"`
+	Vulnerabilities *VulnerabilityCounts `yaml:"-" json:"vulnerabilities,omitempty"`
+	Security        *SecuritySummary     `yaml:"-" json:"security,omitempty"`
 }
 
 type Values struct {
@@ -38,11 +44,39 @@ type ManifestMetadata struct {
 	ServicePorts   []string `json:"servicePorts"`
 }
 
+type ContainerImage struct {
+	Workload      string `json:"workload"`
+	WorkloadKind  string `json:"workloadKind"`
+	Container     string `json:"container"`
+	InitContainer bool   `json:"initContainer,omitempty"`
+	Image         string `json:"image"`
+	Registry      string `json:"registry"`
+	Repository    string `json:"repository"`
+	Tag           string `json:"tag"`
+	Digest        string `json:"digest,omitempty"`
+	Source        string `json:"source,omitempty"`
+	Subchart      string `json:"subchart,omitempty"`
+	Enabled       bool   `json:"enabled"`
+}
+
 type ChartInfo struct {
 	Chart            Chart             `json:"chart"`
 	ImageTag         string            `json:"imageTag"`
 	CanaryTag        string            `json:"canaryTag"`
 	ManifestMetadata *ManifestMetadata `json:"manifestMetadata,omitempty"`
+	Subcharts        []ChartInfo       `json:"subcharts,omitempty"`
+	Alias            string            `json:"alias,omitempty"`
+	Profile          string            `json:"profile,omitempty"`
+	Vulnerabilities  *VulnerabilityCounts `json:"vulnerabilities,omitempty"`
+	Violations       []PolicyViolation `json:"violations,omitempty"`
+	Security         *SecuritySummary  `json:"security,omitempty"`
+	ParseError       *ParseError       `json:"parseError,omitempty"`
+	KubeVersion      string            `json:"kubeVersion,omitempty"`
+	APIVersions      []string          `json:"apiVersions,omitempty"`
+	Manifest         string            `json:"-"`
+	DefaultValues    map[string]interface{} `json:"-"`
+	ValuesSchema     string            `json:"-"`
+	ValuesYAML       string            `json:"-"`
 }
 
 type DockerConfig struct {
@@ -53,9 +87,24 @@ type DockerConfig struct {
 
 type ChartRequest struct {
 	ChartURL    string   `json:"chartUrl"`
+	Version     string   `json:"version,omitempty"`
 	ValuesPath  string   `json:"valuesPath"`
 	SetValues   []string `json:"setValues"`
 	UseHostNetwork bool  `json:"useHostNetwork"`
+	ResolveDigests bool  `json:"resolveDigests"`
+	KubeVersion    string   `json:"kubeVersion,omitempty"`
+	APIVersions    []string `json:"apiVersions,omitempty"`
+}
+
+type ValuesProfile struct {
+	ChartName      string   `json:"chartName"`
+	Name           string   `json:"name"`
+	ValuesFiles    []string `json:"valuesFiles"`
+	SetValues      []string `json:"setValues"`
+	UseHostNetwork bool     `json:"useHostNetwork"`
+	KubeVersion    string   `json:"kubeVersion,omitempty"`
+	APIVersions    []string `json:"apiVersions,omitempty"`
+	ChartVersion   string   `json:"chartVersion,omitempty"`
 }
 
 type RegistryConfig struct {
@@ -69,4 +118,333 @@ type RegistryConfig struct {
 	UpdatedAt   string `json:"updated_at" db:"updated_at"`
 }
 
+// ImageDigest is what the registry reports for an image tag.
+type ImageDigest struct {
+	Registry      string     `json:"registry"`
+	Repository    string     `json:"repository"`
+	Tag           string     `json:"tag"`
+	Digest        string     `json:"digest"`
+	MediaType     string     `json:"mediaType"`
+	Platforms     []string   `json:"platforms"`
+	SizeBytes     int64      `json:"sizeBytes"`
+	Created       *time.Time `json:"created,omitempty"`
+	DigestChanged bool       `json:"digestChanged"`
+	Previous      string     `json:"previousDigest,omitempty"`
+	Error         string     `json:"error,omitempty"`
+}
+
+// VulnerabilityCounts counts scanner findings by severity.
+type VulnerabilityCounts struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Unknown  int `json:"unknown"`
+	Total    int `json:"total"`
+}
+
+// Vulnerability is one finding of a Trivy or Grype report.
+type Vulnerability struct {
+	ID               string `json:"id"`
+	Package          string `json:"package"`
+	InstalledVersion string `json:"installedVersion,omitempty"`
+	FixedVersion     string `json:"fixedVersion,omitempty"`
+	Severity         string `json:"severity"`
+	Title            string `json:"title,omitempty"`
+}
+
+// ScanReport is a scanner report normalized to the image it was run against.
+type ScanReport struct {
+	Scanner         string          `json:"scanner"`
+	Image           string          `json:"image"`
+	Digest          string          `json:"digest,omitempty"`
+	Vulnerabilities []Vulnerability `json:"-"`
+	Counts          VulnerabilityCounts `json:"counts"`
+}
+
+// Policy is a CEL expression that must hold for every rendered object, or
+// once for the chart when Scope is "chart".
+type Policy struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Scope       string `json:"scope"`
+	Expression  string `json:"expression"`
+	Message     string `json:"message,omitempty"`
+	Action      string `json:"action"`
+	Enabled     bool   `json:"enabled"`
+}
+
+type PolicyViolation struct {
+	Policy   string `json:"policy"`
+	Action   string `json:"action"`
+	Resource string `json:"resource,omitempty"`
+	Message  string `json:"message"`
+}
+
+// ValuesFieldError is a value rejected by a chart's values.schema.json. Field
+// is the dotted path used with --set.
+type ValuesFieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValueDoc documents one key of a chart's values.yaml.
+type ValueDoc struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	Default     string `json:"default"`
+	Description string `json:"description,omitempty"`
+}
+
+// ValuesKeyChange is a values key whose default differs between two chart versions.
+type ValuesKeyChange struct {
+	Key      string      `json:"key"`
+	Change   string      `json:"change"`
+	From     interface{} `json:"from,omitempty"`
+	To       interface{} `json:"to,omitempty"`
+	FromType string      `json:"fromType,omitempty"`
+	ToType   string      `json:"toType,omitempty"`
+}
+
+// StaleOverride is a key a values profile sets that the newer version dropped.
+type StaleOverride struct {
+	Profile string      `json:"profile"`
+	Key     string      `json:"key"`
+	Value   interface{} `json:"value"`
+}
+
+// ResourceChange is a rendered resource that differs between two renders.
+type ResourceChange struct {
+	Resource string `json:"resource"`
+	Change   string `json:"change"`
+	Diff     string `json:"diff"`
+}
+
+// ImageChange is a container whose image differs between two renders. From
+// or To is empty when the container only exists on one side.
+type ImageChange struct {
+	Workload     string `json:"workload"`
+	WorkloadKind string `json:"workloadKind"`
+	Container    string `json:"container"`
+	From         string `json:"from,omitempty"`
+	To           string `json:"to,omitempty"`
+}
+
+// WhatIfResult is the impact of bumping one dependency of a stored chart.
+type WhatIfResult struct {
+	Chart           string            `json:"chart"`
+	Version         string            `json:"version"`
+	Dependency      string            `json:"dependency"`
+	FromVersion     string            `json:"fromVersion"`
+	ToVersion       string            `json:"toVersion"`
+	ManifestChanges []ResourceChange  `json:"manifestChanges"`
+	ImageChanges    []ImageChange     `json:"imageChanges"`
+	ValuesChanges   []ValuesKeyChange `json:"valuesChanges"`
+	Violations      []PolicyViolation `json:"violations"`
+	Denied          bool              `json:"denied"`
+	Warnings        []string          `json:"warnings,omitempty"`
+}
+
+// HistoryBackfill reports which published versions of a chart were imported.
+type HistoryBackfill struct {
+	Chart     string                 `json:"chart"`
+	Published int                    `json:"published"`
+	Selected  []string               `json:"selected"`
+	Imported  []string               `json:"imported"`
+	Skipped   []string               `json:"skipped"`
+	Failed    []HistoryImportFailure `json:"failed"`
+}
+
+type HistoryImportFailure struct {
+	Version string `json:"version"`
+	Error   string `json:"error"`
+}
+
+// ParseError is a chart templating or parsing failure sorted into a category
+// that says what the user has to fix.
+type ParseError struct {
+	Stage    string `json:"stage"`
+	Category string `json:"category"`
+	Message  string `json:"message"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Stack    string `json:"stack,omitempty"`
+	Fields   []ValuesFieldError `json:"fields,omitempty"`
+}
 
+// ParseAttempt is a recorded chart import failure or partial result together
+// with the request needed to retry it.
+type ParseAttempt struct {
+	ID           int32        `json:"id"`
+	Request      ChartRequest `json:"request"`
+	ChartName    string       `json:"chartName,omitempty"`
+	ChartVersion string       `json:"chartVersion,omitempty"`
+	Status       string       `json:"status"`
+	Error        ParseError   `json:"error"`
+	Retries      int32        `json:"retries"`
+	CreatedAt    time.Time    `json:"createdAt"`
+	UpdatedAt    time.Time    `json:"updatedAt"`
+}
+
+// App is a workload parsed from a rendered chart into a compose-style spec.
+type App struct {
+	Name    string            `json:"name"`
+	Image   string            `json:"image,omitempty"`
+	Type    string            `json:"type,omitempty"`
+	Ports   []string          `json:"ports"`
+	Configs map[string]string `json:"configs"`
+	Mounts  map[string]string `json:"mounts"`
+}
+
+// TopologyNode is a rendered resource taking part in the runtime topology.
+// External nodes are referenced by the manifest but not rendered by the chart.
+type TopologyNode struct {
+	ID       string `json:"id"`
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Source   string `json:"source,omitempty"`
+	External bool   `json:"external,omitempty"`
+}
+
+// TopologyEdge links two nodes: routes (Ingress/HTTPRoute to Service), selects
+// (Service to workload), mounts (workload to ConfigMap, Secret or PVC) or
+// references (workload env var naming another Service).
+type TopologyEdge struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Type   string `json:"type"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type Topology struct {
+	Chart   string         `json:"chart"`
+	Version string         `json:"version"`
+	Profile string         `json:"profile,omitempty"`
+	Nodes   []TopologyNode `json:"nodes"`
+	Edges   []TopologyEdge `json:"edges"`
+}
+
+// Route is one host and path an Ingress or HTTPRoute sends to a backend.
+type Route struct {
+	Host      string `json:"host"`
+	Path      string `json:"path"`
+	PathType  string `json:"pathType,omitempty"`
+	TLSSecret string `json:"tlsSecret,omitempty"`
+	Service   string `json:"service"`
+	Port      string `json:"port,omitempty"`
+	Kind      string `json:"kind"`
+	Resource  string `json:"resource"`
+	Chart     string `json:"chart"`
+	Version   string `json:"version"`
+	Profile   string `json:"profile,omitempty"`
+}
+
+// RouteConflict is a host and path claimed by more than one chart.
+type RouteConflict struct {
+	Host     string   `json:"host"`
+	Path     string   `json:"path"`
+	PathType string   `json:"pathType,omitempty"`
+	Charts   []string `json:"charts"`
+	Routes   []Route  `json:"routes"`
+}
+
+// ResourceTotals sums CPU in millicores and memory in bytes.
+type ResourceTotals struct {
+	CPURequests    int64 `json:"cpuRequestsMillicores"`
+	CPULimits      int64 `json:"cpuLimitsMillicores"`
+	MemoryRequests int64 `json:"memoryRequestsBytes"`
+	MemoryLimits   int64 `json:"memoryLimitsBytes"`
+}
+
+// WorkloadCapacity is the footprint of one rendered workload. MaxTotal is the
+// footprint at the HPA's maxReplicas, or Total when no HPA targets it.
+type WorkloadCapacity struct {
+	Kind            string         `json:"kind"`
+	Name            string         `json:"name"`
+	Subchart        string         `json:"subchart,omitempty"`
+	Replicas        int64          `json:"replicas"`
+	HPAMin          int64          `json:"hpaMinReplicas,omitempty"`
+	HPAMax          int64          `json:"hpaMaxReplicas,omitempty"`
+	PerReplica      ResourceTotals `json:"perReplica"`
+	Total           ResourceTotals `json:"total"`
+	MaxTotal        ResourceTotals `json:"maxTotal"`
+	MissingRequests []string       `json:"missingRequests,omitempty"`
+	MissingLimits   []string       `json:"missingLimits,omitempty"`
+}
+
+// ChartCapacity is the footprint of a chart version rendered with its
+// default values or a profile, enabled subcharts included.
+type ChartCapacity struct {
+	Chart        string             `json:"chart"`
+	Version      string             `json:"version"`
+	Profile      string             `json:"profile,omitempty"`
+	Workloads    []WorkloadCapacity `json:"workloads"`
+	Replicas     int64              `json:"replicas"`
+	MaxReplicas  int64              `json:"maxReplicas"`
+	Total        ResourceTotals     `json:"total"`
+	MaxTotal     ResourceTotals     `json:"maxTotal"`
+	Claims       int                `json:"persistentVolumeClaims"`
+	StorageBytes int64              `json:"storageBytes"`
+	Dependencies []string           `json:"dependencies,omitempty"`
+	Warnings     []string           `json:"warnings,omitempty"`
+}
+
+// SecurityFinding is one risky setting in a rendered resource. Subchart is
+// the subchart path the resource was rendered from, empty for the chart itself.
+type SecurityFinding struct {
+	Severity string `json:"severity"`
+	Score    int    `json:"score"`
+	Resource string `json:"resource"`
+	Subchart string `json:"subchart,omitempty"`
+	Message  string `json:"message"`
+}
+
+// SecuritySummary is the risk score shown next to a chart or dependency.
+type SecuritySummary struct {
+	Score    int    `json:"score"`
+	Level    string `json:"level"`
+	Findings int    `json:"findings"`
+}
+
+type RBACRole struct {
+	Kind  string   `json:"kind"`
+	Name  string   `json:"name"`
+	Rules []string `json:"rules"`
+}
+
+type RBACBinding struct {
+	Kind     string   `json:"kind"`
+	Name     string   `json:"name"`
+	RoleRef  string   `json:"roleRef"`
+	Subjects []string `json:"subjects"`
+}
+
+// WorkloadSecurity is the pod-level security settings of a rendered workload.
+type WorkloadSecurity struct {
+	Kind               string   `json:"kind"`
+	Name               string   `json:"name"`
+	Subchart           string   `json:"subchart,omitempty"`
+	ServiceAccount     string   `json:"serviceAccount,omitempty"`
+	HostNetwork        bool     `json:"hostNetwork,omitempty"`
+	HostPID            bool     `json:"hostPID,omitempty"`
+	HostIPC            bool     `json:"hostIPC,omitempty"`
+	HostPaths          []string `json:"hostPaths,omitempty"`
+	Privileged         []string `json:"privileged,omitempty"`
+	RunAsRoot          []string `json:"runAsRoot,omitempty"`
+	PrivilegeEscalation []string `json:"privilegeEscalation,omitempty"`
+	Capabilities       []string `json:"capabilities,omitempty"`
+}
+
+// SecurityPosture is the RBAC and pod security surface of a rendered chart.
+type SecurityPosture struct {
+	Chart           string             `json:"chart"`
+	Version         string             `json:"version"`
+	Profile         string             `json:"profile,omitempty"`
+	ServiceAccounts []string           `json:"serviceAccounts"`
+	Roles           []RBACRole         `json:"roles"`
+	Bindings        []RBACBinding      `json:"bindings"`
+	Workloads       []WorkloadSecurity `json:"workloads"`
+	NetworkPolicies []string           `json:"networkPolicies"`
+	Findings        []SecurityFinding  `json:"findings"`
+	Summary         SecuritySummary    `json:"summary"`
+}
@@ -0,0 +1,161 @@
+package pkg
+
+import (
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// collectVendoredSubcharts builds a ChartInfo for every subchart Helm loaded
+// from the chart's charts/ directory. Rendered resources are attributed to a
+// subchart through the "# Source:" path Helm writes above each document, and
+// the subchart's own dependencies are evaluated under its values path.
+func collectVendoredSubcharts(parent *chart.Chart, manifest string, vals chartutil.Values, path string) []ChartInfo {
+	var subcharts []ChartInfo
+	for _, sub := range parent.Dependencies() {
+		if sub == nil || sub.Metadata == nil {
+			continue
+		}
+
+		// Helm renames an aliased subchart to its alias when it processes
+		// dependencies, so look the alias up first to recover the chart name
+		name, alias := sub.Metadata.Name, ""
+		if parent.Metadata != nil {
+			for _, dep := range parent.Metadata.Dependencies {
+				if dep != nil && dep.Alias != "" && dep.Alias == name {
+					name, alias = dep.Name, dep.Alias
+					break
+				}
+			}
+		}
+
+		info := ChartInfo{
+			Chart: Chart{
+				APIVersion:  sub.Metadata.APIVersion,
+				Name:        name,
+				Version:     sub.Metadata.Version,
+				Description: sub.Metadata.Description,
+				Type:        sub.Metadata.Type,
+			},
+			ImageTag:  "N/A",
+			CanaryTag: "N/A",
+			Alias:     alias,
+		}
+		if info.Chart.Type == "" {
+			info.Chart.Type = "application"
+		}
+		for _, dep := range sub.Metadata.Dependencies {
+			if dep == nil {
+				continue
+			}
+			info.Chart.Dependencies = append(info.Chart.Dependencies, Dependency{
+				Name:       dep.Name,
+				Alias:      dep.Alias,
+				Version:    dep.Version,
+				Repository: dep.Repository,
+				Condition:  dep.Condition,
+				Tags:       dep.Tags,
+			})
+		}
+		subPath := path + sub.Name() + "."
+		evaluateDependencyConditions(info.Chart.Dependencies, vals, subPath)
+
+		if subManifest := manifestForChartPath(manifest, sub.ChartFullPath()); subManifest != "" {
+			metadata := extractManifestMetadata(subManifest)
+			info.ImageTag = metadata.ImageTag
+			info.CanaryTag = metadata.CanaryTag
+			info.ManifestMetadata = &metadata
+			info.Manifest = subManifest
+		}
+
+		info.Subcharts = collectVendoredSubcharts(sub, manifest, vals, subPath)
+		subcharts = append(subcharts, info)
+	}
+	return subcharts
+}
+
+// findVendoredSubchart returns the vendored subchart satisfying dep, if any.
+// An aliased dependency only matches the subchart vendored under its alias,
+// so the same chart pulled in twice under different aliases stays apart.
+func findVendoredSubchart(subcharts []ChartInfo, dep Dependency) *ChartInfo {
+	if dep.Alias != "" {
+		for i := range subcharts {
+			if subcharts[i].Alias == dep.Alias {
+				return &subcharts[i]
+			}
+		}
+		return nil
+	}
+	for i := range subcharts {
+		if subcharts[i].Alias == "" && subcharts[i].Chart.Name == dep.Name {
+			return &subcharts[i]
+		}
+	}
+	return nil
+}
+
+// manifestForChartPath keeps only the documents rendered from the chart at
+// chartPath or from any of its own subcharts.
+func manifestForChartPath(manifest, chartPath string) string {
+	var docs []string
+	for _, doc := range splitManifest(manifest) {
+		source := manifestSource(doc)
+		if strings.HasPrefix(source, chartPath+"/templates/") || strings.HasPrefix(source, chartPath+"/charts/") {
+			docs = append(docs, doc)
+		}
+	}
+	return strings.Join(docs, "\n---\n")
+}
+
+// manifestForTemplates keeps only the documents rendered from the templates
+// of the chart at chartPath itself, leaving out its subcharts.
+func manifestForTemplates(manifest, chartPath string) string {
+	var docs []string
+	for _, doc := range splitManifest(manifest) {
+		if strings.HasPrefix(manifestSource(doc), chartPath+"/templates/") {
+			docs = append(docs, doc)
+		}
+	}
+	return strings.Join(docs, "\n---\n")
+}
+
+// renderedChildCharts lists the direct subcharts of the chart at chartPath
+// that rendered any document, in the order they first appear.
+func renderedChildCharts(manifest, chartPath string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, doc := range splitManifest(manifest) {
+		rest, ok := strings.CutPrefix(manifestSource(doc), chartPath+"/charts/")
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(rest, "/")
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func splitManifest(manifest string) []string {
+	var docs []string
+	for _, doc := range strings.Split(manifest, "\n---") {
+		doc = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(doc), "---"))
+		if doc != "" {
+			docs = append(docs, doc)
+		}
+	}
+	return docs
+}
+
+func manifestSource(doc string) string {
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "# Source:") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "# Source:"))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,259 @@
+package pkg
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	TopologyRoutes     = "routes"
+	TopologySelects    = "selects"
+	TopologyMounts     = "mounts"
+	TopologyReferences = "references"
+)
+
+// hostnamePattern finds DNS names inside env values such as URLs or
+// host:port pairs.
+var hostnamePattern = regexp.MustCompile(`[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*`)
+
+// serviceDNSSuffixPattern matches what may follow a service name in its
+// cluster DNS name: the namespace, then optionally svc and the cluster domain.
+var serviceDNSSuffixPattern = regexp.MustCompile(`^\.[a-z0-9-]+(\.svc(\.cluster\.local)?)?$`)
+
+type topologyBuilder struct {
+	nodes map[string]TopologyNode
+	edges map[TopologyEdge]bool
+}
+
+func topologyID(kind, name string) string {
+	return kind + "/" + name
+}
+
+func (b *topologyBuilder) addNode(r manifestResource) string {
+	id := topologyID(r.Kind(), r.Name())
+	b.nodes[id] = TopologyNode{ID: id, Kind: r.Kind(), Name: r.Name(), Source: r.Source}
+	return id
+}
+
+// link adds an edge, creating the target as an external node when the chart
+// doesn't render it.
+func (b *topologyBuilder) link(from, kind, name, edgeType, detail string) {
+	if name == "" {
+		return
+	}
+	to := topologyID(kind, name)
+	if _, ok := b.nodes[to]; !ok {
+		b.nodes[to] = TopologyNode{ID: to, Kind: kind, Name: name, External: true}
+	}
+	b.edges[TopologyEdge{From: from, To: to, Type: edgeType, Detail: detail}] = true
+}
+
+// BuildTopology derives what talks to what from a rendered manifest: routes
+// to Services, Services to the workloads their selectors match, workloads to
+// the ConfigMaps, Secrets and PVCs they use, and workloads to the Services
+// their env vars name by DNS.
+func BuildTopology(manifest string) ([]TopologyNode, []TopologyEdge) {
+	b := &topologyBuilder{nodes: map[string]TopologyNode{}, edges: map[TopologyEdge]bool{}}
+	resources := decodeManifest(manifest)
+
+	// Register every node first so edges only mark what's truly missing as external
+	var services, workloads []manifestResource
+	for _, r := range resources {
+		switch {
+		case r.Kind() == "Service":
+			services = append(services, r)
+		case podSpec(r) != nil:
+			workloads = append(workloads, r)
+		case r.Kind() == "Ingress", r.Kind() == "HTTPRoute", r.Kind() == "GRPCRoute",
+			r.Kind() == "ConfigMap", r.Kind() == "Secret", r.Kind() == "PersistentVolumeClaim":
+		default:
+			continue
+		}
+		b.addNode(r)
+	}
+
+	for _, r := range resources {
+		switch r.Kind() {
+		case "Ingress":
+			linkIngress(b, r)
+		case "HTTPRoute", "GRPCRoute":
+			linkRoute(b, r)
+		}
+	}
+
+	for _, service := range services {
+		selector := nestedMap(service.Object, "spec", "selector")
+		if len(selector) == 0 {
+			continue
+		}
+		for _, workload := range workloads {
+			if labelsMatch(selector, podLabels(workload)) {
+				b.link(topologyID("Service", service.Name()), workload.Kind(), workload.Name(), TopologySelects, "")
+			}
+		}
+	}
+
+	serviceNames := map[string]bool{}
+	for _, service := range services {
+		serviceNames[service.Name()] = true
+	}
+	for _, workload := range workloads {
+		linkPodSpec(b, workload, serviceNames)
+	}
+
+	nodes := make([]TopologyNode, 0, len(b.nodes))
+	for _, node := range b.nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+
+	edges := make([]TopologyEdge, 0, len(b.edges))
+	for edge := range b.edges {
+		edges = append(edges, edge)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		if edges[i].To != edges[j].To {
+			return edges[i].To < edges[j].To
+		}
+		return edges[i].Detail < edges[j].Detail
+	})
+	return nodes, edges
+}
+
+// linkIngress handles both networking.k8s.io/v1 and the older
+// serviceName/servicePort backends.
+func linkIngress(b *topologyBuilder, r manifestResource) {
+	from := topologyID(r.Kind(), r.Name())
+	backendService := func(backend map[string]interface{}) string {
+		if name := nestedString(backend, "service", "name"); name != "" {
+			return name
+		}
+		return nestedString(backend, "serviceName")
+	}
+
+	if backend := nestedMap(r.Object, "spec", "defaultBackend"); backend != nil {
+		b.link(from, "Service", backendService(backend), TopologyRoutes, "default")
+	}
+	if backend := nestedMap(r.Object, "spec", "backend"); backend != nil {
+		b.link(from, "Service", backendService(backend), TopologyRoutes, "default")
+	}
+	for _, rule := range nestedSlice(r.Object, "spec", "rules") {
+		ruleMap, _ := rule.(map[string]interface{})
+		host := nestedString(ruleMap, "host")
+		for _, p := range nestedSlice(ruleMap, "http", "paths") {
+			pathMap, _ := p.(map[string]interface{})
+			detail := host + nestedString(pathMap, "path")
+			b.link(from, "Service", backendService(nestedMap(pathMap, "backend")), TopologyRoutes, detail)
+		}
+	}
+}
+
+// linkRoute handles Gateway API routes, whose backendRefs default to Services.
+func linkRoute(b *topologyBuilder, r manifestResource) {
+	from := topologyID(r.Kind(), r.Name())
+	var hostnames []string
+	for _, hostname := range nestedSlice(r.Object, "spec", "hostnames") {
+		if s, ok := hostname.(string); ok {
+			hostnames = append(hostnames, s)
+		}
+	}
+	for _, rule := range nestedSlice(r.Object, "spec", "rules") {
+		ruleMap, _ := rule.(map[string]interface{})
+		for _, ref := range nestedSlice(ruleMap, "backendRefs") {
+			refMap, _ := ref.(map[string]interface{})
+			kind := nestedString(refMap, "kind")
+			if kind == "" {
+				kind = "Service"
+			}
+			b.link(from, kind, nestedString(refMap, "name"), TopologyRoutes, strings.Join(hostnames, ","))
+		}
+	}
+}
+
+// podLabels returns the labels pods of a workload are created with.
+func podLabels(r manifestResource) map[string]interface{} {
+	switch r.Kind() {
+	case "Pod":
+		return nestedMap(r.Object, "metadata", "labels")
+	case "CronJob":
+		return nestedMap(r.Object, "spec", "jobTemplate", "spec", "template", "metadata", "labels")
+	}
+	return nestedMap(r.Object, "spec", "template", "metadata", "labels")
+}
+
+func labelsMatch(selector, labels map[string]interface{}) bool {
+	for key, value := range selector {
+		if fmt.Sprint(labels[key]) != fmt.Sprint(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// linkPodSpec links a workload to the volumes and env sources it uses and to
+// Services named in its env values.
+func linkPodSpec(b *topologyBuilder, r manifestResource, serviceNames map[string]bool) {
+	from := topologyID(r.Kind(), r.Name())
+	spec := podSpec(r)
+
+	for _, volume := range nestedSlice(spec, "volumes") {
+		v, _ := volume.(map[string]interface{})
+		name := nestedString(v, "name")
+		b.link(from, "ConfigMap", nestedString(v, "configMap", "name"), TopologyMounts, name)
+		b.link(from, "Secret", nestedString(v, "secret", "secretName"), TopologyMounts, name)
+		b.link(from, "PersistentVolumeClaim", nestedString(v, "persistentVolumeClaim", "claimName"), TopologyMounts, name)
+		for _, source := range nestedSlice(v, "projected", "sources") {
+			s, _ := source.(map[string]interface{})
+			b.link(from, "ConfigMap", nestedString(s, "configMap", "name"), TopologyMounts, name)
+			b.link(from, "Secret", nestedString(s, "secret", "name"), TopologyMounts, name)
+		}
+	}
+	if r.Kind() == "StatefulSet" {
+		// Each replica gets a claim named <template>-<statefulset>-<ordinal>,
+		// linked once without the ordinal
+		for _, template := range nestedSlice(r.Object, "spec", "volumeClaimTemplates") {
+			t, _ := template.(map[string]interface{})
+			name := nestedString(t, "metadata", "name")
+			b.link(from, "PersistentVolumeClaim", name+"-"+r.Name(), TopologyMounts, name)
+		}
+	}
+
+	containers, _ := podContainers(spec)
+	for _, container := range containers {
+		for _, envFrom := range nestedSlice(container, "envFrom") {
+			e, _ := envFrom.(map[string]interface{})
+			b.link(from, "ConfigMap", nestedString(e, "configMapRef", "name"), TopologyMounts, "envFrom")
+			b.link(from, "Secret", nestedString(e, "secretRef", "name"), TopologyMounts, "envFrom")
+		}
+		for _, env := range nestedSlice(container, "env") {
+			e, _ := env.(map[string]interface{})
+			name := nestedString(e, "name")
+			b.link(from, "ConfigMap", nestedString(e, "valueFrom", "configMapKeyRef", "name"), TopologyMounts, name)
+			b.link(from, "Secret", nestedString(e, "valueFrom", "secretKeyRef", "name"), TopologyMounts, name)
+			for _, service := range referencedServices(nestedString(e, "value"), serviceNames) {
+				b.link(from, "Service", service, TopologyReferences, name)
+			}
+		}
+	}
+}
+
+// referencedServices returns the Services whose DNS name, short or fully
+// qualified, appears in an env value.
+func referencedServices(value string, serviceNames map[string]bool) []string {
+	var found []string
+	for _, host := range hostnamePattern.FindAllString(strings.ToLower(value), -1) {
+		name, suffix, _ := strings.Cut(host, ".")
+		if !serviceNames[name] {
+			continue
+		}
+		if suffix == "" || serviceDNSSuffixPattern.MatchString("."+suffix) {
+			found = append(found, name)
+		}
+	}
+	return found
+}
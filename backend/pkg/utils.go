@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"runtime/debug"
 	"strings"
 
 	"github.com/jackc/pgx/v5/pgtype"
@@ -13,11 +14,14 @@ import (
 
 	"github.com/ashupednekar/compose/pkg/charts"
 	"github.com/ashupednekar/compose/pkg/spec"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/release"
 )
 
-func TryFetchChart(database *pgxpool.Pool, chartURL, name, version string) (*ChartInfo, error) {
-	chartUtils, err := charts.NewChartUtils(true) 
+// NewAuthenticatedChartUtils creates chart utils logged in to the default
+// registry config, if one is set.
+func NewAuthenticatedChartUtils(database *pgxpool.Pool) (*charts.ChartUtils, error) {
+	chartUtils, err := charts.NewChartUtils(true)
 	if err != nil {
 		return nil, err
 	}
@@ -31,13 +35,23 @@ func TryFetchChart(database *pgxpool.Pool, chartURL, name, version string) (*Cha
 		}
 		chartUtils.Authenticate(authInfo)
 	}
+	return chartUtils, nil
+}
+
+func TryFetchChart(database *pgxpool.Pool, chartURL, name, version, kubeVersion string, apiVersions []string) (*ChartInfo, error) {
+	chartUtils, err := NewAuthenticatedChartUtils(database)
+	if err != nil {
+		return nil, err
+	}
 	
-	
-	chartInfo, _, err := SafeParseChart(chartUtils, ChartRequest{
+	chartInfo, _, err := SafeParseChart(database, chartUtils, ChartRequest{
 		ChartURL: chartURL,
+		Version: version,
 		ValuesPath: "values",
 		SetValues: []string{},
 		UseHostNetwork: false,
+		KubeVersion: kubeVersion,
+		APIVersions: apiVersions,
 	})
 	
 	if err != nil {
@@ -47,7 +61,14 @@ func TryFetchChart(database *pgxpool.Pool, chartURL, name, version string) (*Cha
 	return &chartInfo, nil
 }
 
-func SafeParseChart(chartUtils *charts.ChartUtils, req ChartRequest) (ChartInfo, []spec.App, error) {
+// SafeParseChart templates a chart and parses its apps, recovering from
+// panics in either. A request for a specific version or for target cluster
+// capabilities is rendered once from the pulled chart, since compose always
+// templates the newest version with Helm's default capabilities.
+func SafeParseChart(database *pgxpool.Pool, chartUtils *charts.ChartUtils, req ChartRequest) (ChartInfo, []spec.App, error) {
+	if req.Version != "" || req.KubeVersion != "" || len(req.APIVersions) > 0 {
+		return parseChartVersion(database, req)
+	}
 	
 	valuesPath := req.ValuesPath
 	if valuesPath == "" {
@@ -57,6 +78,7 @@ func SafeParseChart(chartUtils *charts.ChartUtils, req ChartRequest) (ChartInfo,
 	
 	var rel *release.Release
 	var err error
+	var stack string
 	
 	
 	func() {
@@ -64,23 +86,85 @@ func SafeParseChart(chartUtils *charts.ChartUtils, req ChartRequest) (ChartInfo,
 			if r := recover(); r != nil {
 				fmt.Printf("Recovered from panic in Template: %v\n", r)
 				err = fmt.Errorf("chart templating panicked: %v", r)
+				stack = string(debug.Stack())
 			}
 		}()
 		rel, err = chartUtils.Template(req.ChartURL, valuesPath, req.SetValues)
 	}()
 	
 	if err != nil {
-		return ChartInfo{}, nil, fmt.Errorf("chart templating failed: %v", err)
+		return ChartInfo{}, nil, NewParseError(ParseStageTemplate, fmt.Errorf("chart templating failed: %v", err), stack)
 	}
 	if rel == nil {
-		return ChartInfo{}, nil, fmt.Errorf("chart templating returned a nil release")
+		return ChartInfo{}, nil, NewParseError(ParseStageTemplate, fmt.Errorf("chart templating returned a nil release"), "")
 	}
 	
-	if rel == nil {
-		return ChartInfo{}, nil, fmt.Errorf("chart templating returned nil release")
-	}
 	
+	chartInfo := ChartInfoFromRelease(rel, req)
+	
+	// A chart that templates but cannot be parsed into apps is still returned,
+	// with ParseError set so the caller can record the partial result.
+	var apps []spec.App
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("Recovered from panic in Parse: %v\n", r)
+				apps = []spec.App{} 
+				chartInfo.ParseError = NewParseError(ParseStageParse, fmt.Errorf("parsing apps panicked: %v", r), string(debug.Stack()))
+			}
+		}()
+		
+		parsedApps, parseErr := chartUtils.Parse(req.ChartURL, valuesPath, req.SetValues, req.UseHostNetwork)
+		if parseErr != nil {
+			log.Printf("Parse error (non-fatal): %v\n", parseErr)
+			apps = []spec.App{}
+			chartInfo.ParseError = NewParseError(ParseStageParse, fmt.Errorf("parsing apps failed: %v", parseErr), "")
+		} else {
+			apps = parsedApps
+		}
+	}()
+	
+	return chartInfo, apps, nil
+}
+
+// parseChartVersion pulls the requested version, the newest when none is set,
+// renders it with the request's values and capabilities and parses apps from
+// that render.
+func parseChartVersion(database *pgxpool.Pool, req ChartRequest) (ChartInfo, []spec.App, error) {
+	var rel *release.Release
+	var err error
+	var stack string
+	
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				fmt.Printf("Recovered from panic in render: %v\n", r)
+				err = fmt.Errorf("chart templating panicked: %v", r)
+				stack = string(debug.Stack())
+			}
+		}()
+		ch, loadErr := LoadChartURL(database, req.ChartURL, req.Version)
+		if loadErr != nil {
+			err = loadErr
+			return
+		}
+		vals, valuesErr := UserValues([]string{req.ValuesPath}, req.SetValues)
+		if valuesErr != nil {
+			err = valuesErr
+			return
+		}
+		rel, err = renderChart(ch, vals, ch.Name(), "", req.KubeVersion, req.APIVersions)
+	}()
 	
+	if err != nil {
+		return ChartInfo{}, nil, NewParseError(ParseStageTemplate, fmt.Errorf("chart templating failed: %v", err), stack)
+	}
+	return ChartInfoFromRelease(rel, req), AppsFromManifest(rel.Manifest), nil
+}
+
+// ChartInfoFromRelease describes a rendered release: chart metadata and
+// dependencies, values, manifest metadata and vendored subcharts.
+func ChartInfoFromRelease(rel *release.Release, req ChartRequest) ChartInfo {
 	chartName := charts.ExtractName(req.ChartURL)
 	if rel.Chart != nil && rel.Chart.Metadata != nil {
 		chartName = rel.Chart.Metadata.Name
@@ -93,8 +177,11 @@ func SafeParseChart(chartUtils *charts.ChartUtils, req ChartRequest) (ChartInfo,
 			Description: fmt.Sprintf("Chart fetched from %s", req.ChartURL),
 			Type:        "application",
 		},
-		ImageTag:  "N/A",
-		CanaryTag: "N/A",
+		ImageTag:    "N/A",
+		CanaryTag:   "N/A",
+		Manifest:    rel.Manifest,
+		KubeVersion: req.KubeVersion,
+		APIVersions: req.APIVersions,
 	}
 	
 	if rel.Chart != nil && rel.Chart.Metadata != nil {
@@ -106,12 +193,20 @@ func SafeParseChart(chartUtils *charts.ChartUtils, req ChartRequest) (ChartInfo,
 		fmt.Printf("  Dependencies pointer: %p\n", rel.Chart.Metadata.Dependencies)
 		
 		chartInfo.Chart.Version = rel.Chart.Metadata.Version
+		chartInfo.Chart.APIVersion = rel.Chart.Metadata.APIVersion
 		if rel.Chart.Metadata.Description != "" {
 			chartInfo.Chart.Description = rel.Chart.Metadata.Description
 		}
 		if rel.Chart.Metadata.Type != "" {
 			chartInfo.Chart.Type = rel.Chart.Metadata.Type
 		}
+		chartInfo.DefaultValues = rel.Chart.Values
+		chartInfo.ValuesSchema = string(rel.Chart.Schema)
+		for _, file := range rel.Chart.Raw {
+			if file.Name == chartutil.ValuesfileName {
+				chartInfo.ValuesYAML = string(file.Data)
+			}
+		}
 		
 		
 		if rel.Chart.Metadata.Dependencies != nil {
@@ -122,9 +217,11 @@ func SafeParseChart(chartUtils *charts.ChartUtils, req ChartRequest) (ChartInfo,
 					fmt.Printf("  Dependency %d: %+v\n", i+1, dep)
 					chartInfo.Chart.Dependencies = append(chartInfo.Chart.Dependencies, Dependency{
 						Name:       dep.Name,
+						Alias:      dep.Alias,
 						Version:    dep.Version,
 						Repository: dep.Repository,
 						Condition:  dep.Condition,
+						Tags:       dep.Tags,
 					})
 					fmt.Printf("  📦 Added dependency: %s v%s from %s\n", dep.Name, dep.Version, dep.Repository)
 				}
@@ -150,28 +247,20 @@ func SafeParseChart(chartUtils *charts.ChartUtils, req ChartRequest) (ChartInfo,
 		
 		
 		chartInfo.ManifestMetadata = &metadata
+		security := AnalyzeSecurity(rel.Manifest).Summary
+		chartInfo.Security = &security
 	}
-	
-	
-	var apps []spec.App
-	func() {
-		defer func() {
-			if r := recover(); r != nil {
-				log.Printf("Recovered from panic in Parse: %v\n", r)
-				apps = []spec.App{} 
-			}
-		}()
-		
-		parsedApps, parseErr := chartUtils.Parse(req.ChartURL, valuesPath, req.SetValues, req.UseHostNetwork)
-		if parseErr != nil {
-			log.Printf("Parse error (non-fatal): %v\n", parseErr)
-			apps = []spec.App{}
-		} else {
-			apps = parsedApps
+
+	if rel.Chart != nil {
+		vals := computedValues(rel.Chart, rel.Config)
+		evaluateDependencyConditions(chartInfo.Chart.Dependencies, vals, "")
+		chartInfo.Subcharts = collectVendoredSubcharts(rel.Chart, rel.Manifest, vals, "")
+		if len(chartInfo.Subcharts) > 0 {
+			fmt.Printf("📦 Found %d vendored subcharts in charts/ for %s\n", len(chartInfo.Subcharts), chartName)
 		}
-	}()
+	}
 	
-	return chartInfo, apps, nil
+	return chartInfo
 }
 
 func extractManifestMetadata(manifest string) ManifestMetadata {
@@ -278,91 +367,276 @@ func extractManifestMetadata(manifest string) ManifestMetadata {
 	return metadata
 }
 
+// storedDependency is a dependency with the image tags resolved for it before
+// anything is written.
+type storedDependency struct {
+	Dependency
+	ResolvedVersion string
+	ImageTag        string
+	CanaryTag       string
+	Security        *SecuritySummary
+}
+
+// ResolvedDependencyVersion is the version a stored dependency resolved to,
+// falling back to its Chart.yaml version when it was never rendered or pulled.
+func ResolvedDependencyVersion(dep db.GetChartDependenciesRow) string {
+	if dep.ResolvedVersion.Valid {
+		return dep.ResolvedVersion.String
+	}
+	return dep.DependencyVersion
+}
+
+// StoredDependencyKey is the name a stored dependency is rendered under, its
+// alias if set.
+func StoredDependencyKey(dep db.GetChartDependenciesRow) string {
+	return dependencyKey(Dependency{Name: dep.DependencyName, Alias: dep.Alias})
+}
+
+// pendingChart is a chart version whose dependencies are resolved and which
+// is ready to be written, together with the subcharts it vendors.
+type pendingChart struct {
+	ChartInfo    ChartInfo
+	Apps         []spec.App
+	ChartURL     string
+	Dependencies []storedDependency
+	Images       []ContainerImage
+	Security     SecuritySummary
+	Subcharts    []pendingChart
+}
+
+// StoreChartInDB stores a chart version together with its dependencies, apps,
+// images, values and manifest metadata, and a row for every subchart it
+// vendors. Dependencies are resolved first, so a failed fetch writes nothing,
+// and everything is then replaced in a single transaction, which makes
+// re-fetching idempotent.
 func StoreChartInDB(database *pgxpool.Pool, chartInfo ChartInfo, apps []spec.App, chartURL string) (*db.Chart, error) {
 	ctx := context.Background()
-	queries := db.New(database)
-	
-	// Simple approach: always try to get the existing chart first
-	existingChart, err := queries.GetChart(ctx, chartInfo.Chart.Name)
+
+	// Resolve dependencies before opening the transaction, fetching them
+	// from the network is slow and may fail
+	pending, err := resolveChart(database, chartInfo, apps, chartURL)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := database.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+	queries := db.New(database).WithTx(tx)
+
+	storedChart, err := storeChart(ctx, queries, tx, *pending, false, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit chart %s v%s: %v", storedChart.Name, storedChart.Version, err)
+	}
+	return storedChart, nil
+}
+
+// resolveChart resolves the dependencies of a chart and of the subcharts it
+// vendors, pulling those that aren't vendored to read their image tags. The
+// security of the chart and of each dependency is scored here so listing
+// charts doesn't analyze their manifests again.
+func resolveChart(database *pgxpool.Pool, chartInfo ChartInfo, apps []spec.App, chartURL string) (*pendingChart, error) {
+	posture := AnalyzeSecurity(chartInfo.Manifest)
+	rendered := RenderedSubcharts(chartInfo.Manifest)
+	pending := &pendingChart{
+		ChartInfo: chartInfo,
+		Apps:      apps,
+		ChartURL:  chartURL,
+		Images:    ExtractContainerImages(chartInfo.Manifest),
+		Security:  posture.Summary,
+	}
+
+	for _, dep := range chartInfo.Chart.Dependencies {
+		log.Printf("🔍 Resolving dependency %s v%s\n", dep.Name, dep.Version)
+		
+		// Try to fetch dependency chart info to get image/canary tags
+		resolved := storedDependency{Dependency: dep, ImageTag: "N/A", CanaryTag: "N/A"}
+		if rendered[dependencyKey(dep)] {
+			security := SubchartSecurity(posture.Findings, dependencyKey(dep))
+			resolved.Security = &security
+		}
+
+		depChartURL := dep.Repository
+		if depChartURL != "" && !strings.HasSuffix(depChartURL, "/"+dep.Name) {
+			depChartURL = depChartURL + "/" + dep.Name
+		}
+
+		if sub := findVendoredSubchart(chartInfo.Subcharts, dep); sub != nil {
+			// Vendored under charts/, so it was already rendered with the parent
+			resolved.ResolvedVersion = sub.Chart.Version
+			resolved.ImageTag = sub.ImageTag
+			resolved.CanaryTag = sub.CanaryTag
+			log.Printf("📦 Using vendored subchart %s v%s\n", sub.Chart.Name, sub.Chart.Version)
+
+			subChartURL := depChartURL
+			if subChartURL == "" {
+				subChartURL = chartURL
+			}
+			subPending, subErr := resolveChart(database, *sub, AppsFromManifest(sub.Manifest), subChartURL)
+			if subErr != nil {
+				return nil, fmt.Errorf("failed to resolve vendored subchart %s: %v", sub.Chart.Name, subErr)
+			}
+			pending.Subcharts = append(pending.Subcharts, *subPending)
+		} else if dep.Repository != "" {
+			// Declared but missing from charts/, fetch it to get its image
+			// tags. Helm drops disabled dependencies before rendering, so
+			// they are fetched too, for their images to be listed as disabled.
+			log.Printf("🔍 Attempting to fetch dependency info from: %s\n", depChartURL)
+			if depChartInfo, depErr := TryFetchChart(database, depChartURL, dep.Name, dep.Version, chartInfo.KubeVersion, chartInfo.APIVersions); depErr == nil {
+				resolved.ResolvedVersion = depChartInfo.Chart.Version
+				resolved.ImageTag = depChartInfo.ImageTag
+				resolved.CanaryTag = depChartInfo.CanaryTag
+				if resolved.Security == nil {
+					resolved.Security = depChartInfo.Security
+				}
+				log.Printf("✅ Got dependency tags: image=%s, canary=%s\n", resolved.ImageTag, resolved.CanaryTag)
+				for _, image := range ExtractContainerImages(depChartInfo.Manifest) {
+					image.Subchart = strings.TrimSuffix(dependencyKey(dep)+"/"+image.Subchart, "/")
+					image.Enabled = dep.Enabled
+					pending.Images = append(pending.Images, image)
+				}
+			} else if !dep.Enabled {
+				// Only the inventory needs it, don't fail the chart over it
+				log.Printf("⚠️ Could not fetch disabled dependency %s, its images aren't listed: %v\n", dep.Name, depErr)
+			} else {
+				log.Printf("⚠️ Could not fetch dependency info: %v\n", depErr)
+				return nil, fmt.Errorf("failed to fetch dependency %s: %v", dep.Name, depErr)
+			}
+		}
+		pending.Dependencies = append(pending.Dependencies, resolved)
+	}
+	return pending, nil
+}
+
+// dependencyKey is the name a dependency is rendered under, its alias if set.
+func dependencyKey(dep Dependency) string {
+	if dep.Alias != "" {
+		return dep.Alias
+	}
+	return dep.Name
+}
+
+// storeChart writes a resolved chart version and its vendored subcharts with
+// queries bound to tx. A vendored subchart never replaces a version that was
+// fetched directly, and stored records which versions exist only as
+// subcharts so the same one vendored twice is written once.
+func storeChart(ctx context.Context, queries *db.Queries, tx db.DBTX, pending pendingChart, vendored bool, stored map[string]bool) (*db.Chart, error) {
+	chartInfo := pending.ChartInfo
+	key := chartInfo.Chart.Name + "@" + chartInfo.Chart.Version
+
+	// Each version is its own row, so look it up by name and version
+	existing, err := queries.GetChartVersion(ctx, db.GetChartVersionParams{
+		Name:    chartInfo.Chart.Name,
+		Version: chartInfo.Chart.Version,
+	})
+	if vendored {
+		if stored[key] || (err == nil && !existing.Vendored) {
+			log.Printf("📦 Keeping stored chart %s v%s over the vendored copy\n", chartInfo.Chart.Name, chartInfo.Chart.Version)
+			return &existing, nil
+		}
+		stored[key] = true
+	}
 	
 	var storedChart db.Chart
+	apiVersionsJSON, _ := json.Marshal(chartInfo.APIVersions)
 	
 	if err != nil {
-		// Chart doesn't exist, create new one
-		log.Printf("📝 Creating new chart: %s\n", chartInfo.Chart.Name)
+		// Version doesn't exist, create it. Which version is latest is
+		// recomputed by semver precedence once it is stored.
+		log.Printf("📝 Creating new chart: %s v%s\n", chartInfo.Chart.Name, chartInfo.Chart.Version)
 		storedChart, err = queries.CreateChart(ctx, db.CreateChartParams{
-			Name:        chartInfo.Chart.Name,
-			Version:     chartInfo.Chart.Version,
-			Type:        chartInfo.Chart.Type,
-			ChartUrl:    chartURL,
+			Name:            chartInfo.Chart.Name,
+			Version:         chartInfo.Chart.Version,
+			Description:     pgtype.Text{String: chartInfo.Chart.Description, Valid: chartInfo.Chart.Description != ""},
+			Type:            chartInfo.Chart.Type,
+			ChartUrl:        pending.ChartURL,
+			ImageTag:        pgtype.Text{String: chartInfo.ImageTag, Valid: true},
+			CanaryTag:       pgtype.Text{String: chartInfo.CanaryTag, Valid: true},
+			Manifest:        pgtype.Text{String: chartInfo.Manifest, Valid: chartInfo.Manifest != ""},
+			IsLatest:        pgtype.Bool{Bool: false, Valid: true},
+			KubeVersion:     pgtype.Text{String: chartInfo.KubeVersion, Valid: chartInfo.KubeVersion != ""},
+			ApiVersions:     pgtype.Text{String: string(apiVersionsJSON), Valid: len(chartInfo.APIVersions) > 0},
+			Vendored:        vendored,
+			SecuritySummary: EncodeSecuritySummary(&pending.Security),
 		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to create chart: %v", err)
 		}
-		
-		if chartInfo.ManifestMetadata != nil {
-			err = UpdateChartManifestMetadata(database, int64(storedChart.ID), *chartInfo.ManifestMetadata)
-			if err != nil {
-				log.Printf("⚠️  Warning: failed to update manifest metadata: %v\n", err)
-			}
-		}
 	} else {
-		// Chart exists, use the existing one
-		log.Printf("📝 Using existing chart: %s (ID: %d)\n", chartInfo.Chart.Name, existingChart.ID)
-		storedChart = existingChart
-		
-		// Clear existing dependencies
-		queries.DeleteChartDependencies(ctx, int32(storedChart.ID))
+		// Version exists, replace what was rendered for it
+		log.Printf("📝 Replacing existing chart: %s v%s\n", chartInfo.Chart.Name, chartInfo.Chart.Version)
+		storedChart, err = queries.UpdateChart(ctx, db.UpdateChartParams{
+			Version:         chartInfo.Chart.Version,
+			Description:     pgtype.Text{String: chartInfo.Chart.Description, Valid: chartInfo.Chart.Description != ""},
+			Type:            chartInfo.Chart.Type,
+			ChartUrl:        pending.ChartURL,
+			ImageTag:        pgtype.Text{String: chartInfo.ImageTag, Valid: true},
+			CanaryTag:       pgtype.Text{String: chartInfo.CanaryTag, Valid: true},
+			Manifest:        pgtype.Text{String: chartInfo.Manifest, Valid: chartInfo.Manifest != ""},
+			KubeVersion:     pgtype.Text{String: chartInfo.KubeVersion, Valid: chartInfo.KubeVersion != ""},
+			ApiVersions:     pgtype.Text{String: string(apiVersionsJSON), Valid: len(chartInfo.APIVersions) > 0},
+			Name:            chartInfo.Chart.Name,
+			Version_2:       chartInfo.Chart.Version,
+			Vendored:        vendored,
+			SecuritySummary: EncodeSecuritySummary(&pending.Security),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update chart: %v", err)
+		}
+		if err := queries.DeleteChartDependencies(ctx, int32(storedChart.ID)); err != nil {
+			return nil, fmt.Errorf("failed to clear dependencies: %v", err)
+		}
+		if err := queries.DeleteChartApps(ctx, int32(storedChart.ID)); err != nil {
+			return nil, fmt.Errorf("failed to clear apps: %v", err)
+		}
+	}
+	
+	if chartInfo.ManifestMetadata != nil {
+		err = UpdateChartManifestMetadata(tx, int64(storedChart.ID), *chartInfo.ManifestMetadata)
+		if err != nil {
+			return nil, err
+		}
 	}
 	
-	log.Printf("🚀 REACHED DEPENDENCY STORAGE SECTION!\n")
-	log.Printf("🔍 DEBUG: storedChart pointer: %p\n", &storedChart)
-	log.Printf("🔍 DEBUG: storedChart ID: %d\n", storedChart.ID)
+	if err := StoreChartValues(ctx, queries, storedChart.ID, chartInfo); err != nil {
+		return nil, fmt.Errorf("failed to store default values and schema: %v", err)
+	}
 	
 	// Store dependencies
-	log.Printf("🔍 DEBUG: About to store %d dependencies for chart %s (ID: %d)\n", len(chartInfo.Chart.Dependencies), chartInfo.Chart.Name, storedChart.ID)
-	log.Printf("🔍 DEBUG: Dependencies array: %+v\n", chartInfo.Chart.Dependencies)
-	for i, dep := range chartInfo.Chart.Dependencies {
-		log.Printf("🔍 DEBUG: Processing dependency %d: %+v\n", i+1, dep)
-		
-		// Try to fetch dependency chart info to get image/canary tags
-		var depImageTag, depCanaryTag string = "N/A", "N/A"
-		
-		if dep.Repository != "" {
-			// Try to fetch the dependency chart to get its image tags
-			depChartURL := dep.Repository
-			if !strings.HasSuffix(depChartURL, "/"+dep.Name) {
-				depChartURL = depChartURL + "/" + dep.Name
-			}
-			
-			log.Printf("🔍 Attempting to fetch dependency info from: %s\n", depChartURL)
-			if depChartInfo, depErr := TryFetchChart(database, depChartURL, dep.Name, dep.Version); depErr == nil {
-				depImageTag = depChartInfo.ImageTag
-				depCanaryTag = depChartInfo.CanaryTag
-				log.Printf("✅ Got dependency tags: image=%s, canary=%s\n", depImageTag, depCanaryTag)
-			} else {
-				log.Printf("⚠️ Could not fetch dependency info: %v\n", depErr)
-				return nil, fmt.Errorf("failed to fetch dependency %s: %v", dep.Name, depErr)
-			}
-		}
-		
-		// For now, store dependency without tags until we can update the schema properly
+	for _, dep := range pending.Dependencies {
+		tagsJSON, _ := json.Marshal(dep.Tags)
 		depResult, err := queries.CreateDependency(ctx, db.CreateDependencyParams{
 			ChartID:           int32(storedChart.ID),
 			DependencyName:    dep.Name,
 			DependencyVersion: dep.Version,
 			Repository:        pgtype.Text{String: dep.Repository, Valid: dep.Repository != ""},
 			ConditionField:    pgtype.Text{String: dep.Condition, Valid: dep.Condition != ""},
+			ImageTag:          pgtype.Text{String: dep.ImageTag, Valid: true},
+			CanaryTag:         pgtype.Text{String: dep.CanaryTag, Valid: true},
+			Enabled:           dep.Enabled,
+			Tags:              pgtype.Text{String: string(tagsJSON), Valid: len(dep.Tags) > 0},
+			ResolvedVersion:   pgtype.Text{String: dep.ResolvedVersion, Valid: dep.ResolvedVersion != ""},
+			Alias:             dep.Alias,
+			SecuritySummary:   EncodeSecuritySummary(dep.Security),
 		})
 		if err != nil {
-			log.Printf("❌ ERROR: failed to store dependency %s: %v\n", dep.Name, err)
-		} else {
-			log.Printf("✅ SUCCESS: Stored dependency: %s v%s (ID: %d)\n", dep.Name, dep.Version, depResult.ID)
+			return nil, fmt.Errorf("failed to store dependency %s: %v", dependencyKey(dep.Dependency), err)
 		}
+		log.Printf("✅ Stored dependency: %s v%s (ID: %d)\n", dependencyKey(dep.Dependency), dep.Version, depResult.ID)
+	}
+
+	if err := StoreImages(ctx, queries, storedChart.ID, storedChart.Version, pgtype.Int4{}, pending.Images); err != nil {
+		return nil, fmt.Errorf("failed to store image inventory: %v", err)
 	}
 
 	// Store apps
-	for _, app := range apps {
+	for _, app := range pending.Apps {
 		portsJSON, _ := json.Marshal(app.Ports)
 		configsJSON, _ := json.Marshal(app.Configs)
 		mountsJSON, _ := json.Marshal(app.Mounts)
@@ -377,14 +651,27 @@ func StoreChartInDB(database *pgxpool.Pool, chartInfo ChartInfo, apps []spec.App
 			Mounts:  pgtype.Text{String: string(mountsJSON), Valid: len(app.Mounts) > 0},
 		})
 		if err != nil {
-			log.Printf("Warning: failed to store app %s: %v\n", app.Name, err)
+			return nil, fmt.Errorf("failed to store app %s: %v", app.Name, err)
+		}
+	}
+
+	for _, sub := range pending.Subcharts {
+		if _, err := storeChart(ctx, queries, tx, sub, true, stored); err != nil {
+			return nil, fmt.Errorf("failed to store vendored subchart %s: %v", sub.ChartInfo.Chart.Name, err)
 		}
 	}
 	
+	latest, err := RecomputeLatest(ctx, queries, storedChart.Name)
+	if err != nil {
+		return nil, err
+	}
+	storedChart.IsLatest = pgtype.Bool{Bool: latest == storedChart.Version, Valid: true}
 	return &storedChart, nil
 }
 
-func UpdateChartManifestMetadata(database *pgxpool.Pool, chartID int64, metadata ManifestMetadata) error {
+// UpdateChartManifestMetadata stores the ingress paths, images and ports
+// extracted from a chart's manifest, using the pool or a transaction.
+func UpdateChartManifestMetadata(database db.DBTX, chartID int64, metadata ManifestMetadata) error {
 	ctx := context.Background()
 	
 	// Convert arrays to JSON
@@ -0,0 +1,167 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	ValuesKeyAdded          = "added"
+	ValuesKeyRemoved        = "removed"
+	ValuesKeyChangedDefault = "changed-default"
+	ValuesKeyTypeChanged    = "type-changed"
+)
+
+// DecodeDefaultValues decodes a stored default_values column, keeping
+// integers apart from floats so type changes between them are visible.
+func DecodeDefaultValues(data string) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(data)))
+	decoder.UseNumber()
+	values := map[string]interface{}{}
+	if err := decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("invalid stored default values: %v", err)
+	}
+	return values, nil
+}
+
+// FlattenValues maps the dotted path of every leaf to its value. Lists and
+// empty maps are leaves.
+func FlattenValues(values map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	flattenValues(values, "", out)
+	return out
+}
+
+func flattenValues(values map[string]interface{}, prefix string, out map[string]interface{}) {
+	for key, value := range values {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenValues(nested, path, out)
+			continue
+		}
+		out[path] = value
+	}
+}
+
+// jsonValueType names the type of a decoded value the way values docs do.
+func jsonValueType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			return "float"
+		}
+		return "int"
+	case float64:
+		if v == float64(int64(v)) {
+			return "int"
+		}
+		return "float"
+	case int, int64:
+		return "int"
+	case string:
+		return "string"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// DiffValues compares two sets of default values key by key.
+func DiffValues(from, to map[string]interface{}) []ValuesKeyChange {
+	fromKeys := FlattenValues(from)
+	toKeys := FlattenValues(to)
+
+	changes := []ValuesKeyChange{}
+	for key, fromValue := range fromKeys {
+		toValue, ok := toKeys[key]
+		if !ok {
+			changes = append(changes, ValuesKeyChange{
+				Key:      key,
+				Change:   ValuesKeyRemoved,
+				From:     fromValue,
+				FromType: jsonValueType(fromValue),
+			})
+			continue
+		}
+		change := ValuesKeyChange{
+			Key:      key,
+			From:     fromValue,
+			To:       toValue,
+			FromType: jsonValueType(fromValue),
+			ToType:   jsonValueType(toValue),
+		}
+		switch {
+		case change.FromType != change.ToType && fromValue != nil && toValue != nil:
+			change.Change = ValuesKeyTypeChanged
+		case !reflect.DeepEqual(fromValue, toValue):
+			change.Change = ValuesKeyChangedDefault
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	for key, toValue := range toKeys {
+		if _, ok := fromKeys[key]; !ok {
+			changes = append(changes, ValuesKeyChange{
+				Key:    key,
+				Change: ValuesKeyAdded,
+				To:     toValue,
+				ToType: jsonValueType(toValue),
+			})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+// valuesKeyExists reports whether key can be set in values: it is a leaf, a
+// map above leaves, or sits under a map left empty for free-form entries.
+func valuesKeyExists(flat map[string]interface{}, key string) bool {
+	if _, ok := flat[key]; ok {
+		return true
+	}
+	for existing, value := range flat {
+		if strings.HasPrefix(existing, key+".") {
+			return true
+		}
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) == 0 && strings.HasPrefix(key, existing+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// StaleOverrides returns the keys a profile sets that exist in the from
+// defaults but no longer exist in the to defaults.
+func StaleOverrides(profile string, overrides, from, to map[string]interface{}) []StaleOverride {
+	fromKeys := FlattenValues(from)
+	toKeys := FlattenValues(to)
+
+	var stale []StaleOverride
+	for key, value := range FlattenValues(overrides) {
+		if valuesKeyExists(fromKeys, key) && !valuesKeyExists(toKeys, key) {
+			stale = append(stale, StaleOverride{Profile: profile, Key: key, Value: value})
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool {
+		return stale[i].Key < stale[j].Key
+	})
+	return stale
+}
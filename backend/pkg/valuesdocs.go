@@ -0,0 +1,225 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// # -- description, optionally starting with a (type)
+	docCommentPattern = regexp.MustCompile(`^#\s*--\s?(?:\(([^)]+)\)\s*)?(.*)$`)
+	// # some.key -- description, the older key-path form
+	keyDocCommentPattern = regexp.MustCompile(`^#\s*([\w.\-\[\]]+)\s+--\s?(?:\(([^)]+)\)\s*)?(.*)$`)
+	// # @default -- description of the default
+	defaultCommentPattern = regexp.MustCompile(`^#\s*@default\s+--\s?(.*)$`)
+)
+
+// valueComment is a parsed helm-docs comment block.
+type valueComment struct {
+	Type        string
+	Description string
+	Default     string
+}
+
+// ParseValuesDocs extracts the documented values of a values.yaml following
+// the helm-docs conventions: a "# --" comment above a key documents it, later
+// "#" lines continue the description and "# @default --" replaces the shown
+// default. Every leaf key is listed, documented maps are listed as a whole.
+func ParseValuesDocs(valuesYAML string) ([]ValueDoc, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(valuesYAML), &root); err != nil {
+		return nil, fmt.Errorf("invalid values.yaml: %v", err)
+	}
+	docs := []ValueDoc{}
+	if len(root.Content) == 0 {
+		return docs, nil
+	}
+
+	// Key-path comments may sit anywhere in the file, so collect them first
+	keyComments := map[string]valueComment{}
+	collectKeyComments(&root, keyComments)
+
+	walkValuesNode(root.Content[0], "", keyComments, &docs)
+	return docs, nil
+}
+
+func collectKeyComments(node *yaml.Node, out map[string]valueComment) {
+	for _, comment := range []string{node.HeadComment, node.LineComment, node.FootComment} {
+		var current string
+		for _, line := range strings.Split(comment, "\n") {
+			line = strings.TrimSpace(line)
+			if match := keyDocCommentPattern.FindStringSubmatch(line); match != nil && !strings.HasPrefix(match[1], "-") {
+				current = match[1]
+				out[current] = valueComment{Type: match[2], Description: strings.TrimSpace(match[3])}
+				continue
+			}
+			if current == "" || !strings.HasPrefix(line, "#") {
+				current = ""
+				continue
+			}
+			doc := out[current]
+			if match := defaultCommentPattern.FindStringSubmatch(line); match != nil {
+				doc.Default = strings.TrimSpace(match[1])
+			} else {
+				doc.Description = strings.TrimSpace(doc.Description + " " + strings.TrimSpace(strings.TrimPrefix(line, "#")))
+			}
+			out[current] = doc
+		}
+	}
+	for _, child := range node.Content {
+		collectKeyComments(child, out)
+	}
+}
+
+// parseHeadComment reads the last "# --" block of a key's head comment.
+func parseHeadComment(comment string) (valueComment, bool) {
+	var doc valueComment
+	found := false
+	for _, line := range strings.Split(comment, "\n") {
+		line = strings.TrimSpace(line)
+		if match := docCommentPattern.FindStringSubmatch(line); match != nil {
+			doc = valueComment{Type: match[1], Description: strings.TrimSpace(match[2])}
+			found = true
+			continue
+		}
+		if !found {
+			continue
+		}
+		if match := defaultCommentPattern.FindStringSubmatch(line); match != nil {
+			doc.Default = strings.TrimSpace(match[1])
+		} else if strings.HasPrefix(line, "#") {
+			doc.Description = strings.TrimSpace(doc.Description + " " + strings.TrimSpace(strings.TrimPrefix(line, "#")))
+		} else {
+			// A blank line ends the block
+			found = false
+			doc = valueComment{}
+		}
+	}
+	return doc, found
+}
+
+func walkValuesNode(node *yaml.Node, prefix string, keyComments map[string]valueComment, docs *[]ValueDoc) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		key := keyNode.Value
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		comment, documented := parseHeadComment(keyNode.HeadComment)
+		if keyComment, ok := keyComments[key]; ok {
+			comment, documented = keyComment, true
+		}
+
+		// Walk into non-empty maps unless the map itself is documented
+		if valueNode.Kind == yaml.MappingNode && len(valueNode.Content) > 0 && !documented {
+			walkValuesNode(valueNode, key, keyComments, docs)
+			continue
+		}
+
+		doc := ValueDoc{
+			Key:         key,
+			Type:        comment.Type,
+			Default:     comment.Default,
+			Description: comment.Description,
+		}
+		if doc.Type == "" {
+			doc.Type = valueType(valueNode)
+		}
+		if doc.Default == "" {
+			doc.Default = valueDefault(valueNode)
+		}
+		*docs = append(*docs, doc)
+	}
+}
+
+func valueType(node *yaml.Node) string {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "list"
+	}
+	switch node.Tag {
+	case "!!int":
+		return "int"
+	case "!!float":
+		return "float"
+	case "!!bool":
+		return "bool"
+	default:
+		return "string"
+	}
+}
+
+// valueDefault renders a default value as compact JSON, like helm-docs does.
+func valueDefault(node *yaml.Node) string {
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return node.Value
+	}
+	data, err := json.Marshal(normalizeYAMLValue(value))
+	if err != nil {
+		return node.Value
+	}
+	return string(data)
+}
+
+// normalizeYAMLValue converts map[interface{}]interface{} keys so the value
+// can be encoded as JSON.
+func normalizeYAMLValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeYAMLValue(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[fmt.Sprint(key)] = normalizeYAMLValue(item)
+		}
+		return out
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeYAMLValue(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// ValuesDocsMarkdown renders documented values as the table helm-docs puts
+// in a chart README.
+func ValuesDocsMarkdown(chartName, version string, docs []ValueDoc) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", chartName)
+	fmt.Fprintf(&sb, "Version: %s\n\n", version)
+	sb.WriteString("## Values\n\n")
+	sb.WriteString("| Key | Type | Default | Description |\n")
+	sb.WriteString("|-----|------|---------|-------------|\n")
+	for _, doc := range docs {
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n",
+			markdownCell(doc.Key),
+			markdownCell(doc.Type),
+			"`"+markdownCell(doc.Default)+"`",
+			markdownCell(doc.Description),
+		)
+	}
+	return sb.String()
+}
+
+func markdownCell(value string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(value, "|", "\\|"), "\n", " ")
+}
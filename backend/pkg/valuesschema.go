@@ -0,0 +1,186 @@
+package pkg
+
+import (
+	"bytes"
+	"chartpaper/internal/db"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/strvals"
+)
+
+// ValuesValidationError lists every field of a values set rejected by the
+// chart's values.schema.json.
+type ValuesValidationError struct {
+	Errors []ValuesFieldError
+}
+
+func (e *ValuesValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		messages = append(messages, fmt.Sprintf("%s: %s", fieldErr.Field, fieldErr.Message))
+	}
+	return "values don't match values.schema.json: " + strings.Join(messages, "; ")
+}
+
+// StoreChartValues keeps the default values, values.yaml and schema of a
+// stored chart version.
+func StoreChartValues(ctx context.Context, queries *db.Queries, chartID int32, chartInfo ChartInfo) error {
+	defaultsJSON, _ := json.Marshal(chartInfo.DefaultValues)
+	return queries.UpsertChartValues(ctx, db.UpsertChartValuesParams{
+		ChartID:       chartID,
+		DefaultValues: pgtype.Text{String: string(defaultsJSON), Valid: chartInfo.DefaultValues != nil},
+		ValuesSchema:  pgtype.Text{String: chartInfo.ValuesSchema, Valid: chartInfo.ValuesSchema != ""},
+		ValuesYaml:    pgtype.Text{String: chartInfo.ValuesYAML, Valid: chartInfo.ValuesYAML != ""},
+	})
+}
+
+// ValidateValues checks values against a values.schema.json document.
+func ValidateValues(schema []byte, values map[string]interface{}) ([]ValuesFieldError, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(schema))
+	if err != nil {
+		return nil, fmt.Errorf("invalid values.schema.json: %v", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("file:///values.schema.json", doc); err != nil {
+		return nil, fmt.Errorf("invalid values.schema.json: %v", err)
+	}
+	validator, err := compiler.Compile("file:///values.schema.json")
+	if err != nil {
+		return nil, fmt.Errorf("invalid values.schema.json: %v", err)
+	}
+
+	// Round trip through JSON so YAML-decoded numbers become json.Number
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode values: %v", err)
+	}
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode values: %v", err)
+	}
+
+	var validationErr *jsonschema.ValidationError
+	if err := validator.Validate(instance); err != nil {
+		if !errors.As(err, &validationErr) {
+			return nil, err
+		}
+		var fieldErrors []ValuesFieldError
+		collectFieldErrors(validationErr, &fieldErrors)
+		return fieldErrors, nil
+	}
+	return nil, nil
+}
+
+// collectFieldErrors flattens a validation error into its leaf causes, which
+// are the ones naming a concrete field and constraint.
+func collectFieldErrors(err *jsonschema.ValidationError, out *[]ValuesFieldError) {
+	if len(err.Causes) == 0 {
+		message := err.Error()
+		if unit := err.BasicOutput(); unit.Error != nil {
+			message = unit.Error.String()
+		}
+		*out = append(*out, ValuesFieldError{Field: valuesField(err.InstanceLocation), Message: message})
+		return
+	}
+	for _, cause := range err.Causes {
+		collectFieldErrors(cause, out)
+	}
+}
+
+// valuesField turns an instance location into the dotted path used by --set.
+func valuesField(location []string) string {
+	if len(location) == 0 {
+		return "(root)"
+	}
+	return strings.Join(location, ".")
+}
+
+// helmSchemaErrorPattern matches the lines of Helm's own schema failures:
+// "- at '/image/tag': got number, want string"
+var helmSchemaErrorPattern = regexp.MustCompile(`(?m)^\s*- at '([^']*)': (.+)$`)
+
+// schemaFieldErrors extracts field errors from a Helm schema validation message.
+func schemaFieldErrors(message string) []ValuesFieldError {
+	var fieldErrors []ValuesFieldError
+	for _, match := range helmSchemaErrorPattern.FindAllStringSubmatch(message, -1) {
+		location := strings.Split(strings.TrimPrefix(match[1], "/"), "/")
+		if match[1] == "" || match[1] == "/" {
+			location = nil
+		}
+		fieldErrors = append(fieldErrors, ValuesFieldError{Field: valuesField(location), Message: match[2]})
+	}
+	return fieldErrors
+}
+
+// UserValues merges values files and --set style overrides the way a profile
+// passes them to Helm. The "values" placeholder stands for the chart defaults.
+func UserValues(valuesFiles []string, setValues []string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	for _, file := range valuesFiles {
+		if file == "" || file == "values" {
+			continue
+		}
+		fileValues, err := chartutil.ReadValuesFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read values file %s: %v", file, err)
+		}
+		values = mergeValueMaps(values, fileValues)
+	}
+	for _, value := range setValues {
+		if err := strvals.ParseInto(value, values); err != nil {
+			return nil, fmt.Errorf("invalid set value %q: %v", value, err)
+		}
+	}
+	return values, nil
+}
+
+// ValidateChartValues checks user values merged over the defaults of a stored
+// chart version against its schema. It returns a *ValuesValidationError when
+// fields are rejected and nil when the chart ships no schema. Subchart schemas
+// are not checked here; Helm still enforces them when rendering.
+func ValidateChartValues(database *pgxpool.Pool, chartID int32, valuesFiles []string, setValues []string) error {
+	queries := db.New(database)
+	chartValues, err := queries.GetChartValues(context.Background(), chartID)
+	if err != nil || !chartValues.ValuesSchema.Valid {
+		return nil
+	}
+
+	defaults := map[string]interface{}{}
+	if chartValues.DefaultValues.Valid {
+		json.Unmarshal([]byte(chartValues.DefaultValues.String), &defaults)
+	}
+	return validateUserValues(chartValues.ValuesSchema.String, defaults, valuesFiles, setValues)
+}
+
+// ValidateRequestValues checks the values of a fetch request against the
+// schema of the chart it rendered, before the chart is stored.
+func ValidateRequestValues(chartInfo ChartInfo, req ChartRequest) error {
+	if chartInfo.ValuesSchema == "" {
+		return nil
+	}
+	return validateUserValues(chartInfo.ValuesSchema, chartInfo.DefaultValues, []string{req.ValuesPath}, req.SetValues)
+}
+
+func validateUserValues(schema string, defaults map[string]interface{}, valuesFiles []string, setValues []string) error {
+	userValues, err := UserValues(valuesFiles, setValues)
+	if err != nil {
+		return err
+	}
+	fieldErrors, err := ValidateValues([]byte(schema), mergeValueMaps(defaults, userValues))
+	if err != nil {
+		return err
+	}
+	if len(fieldErrors) > 0 {
+		return &ValuesValidationError{Errors: fieldErrors}
+	}
+	return nil
+}
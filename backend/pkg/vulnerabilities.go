@@ -0,0 +1,259 @@
+package pkg
+
+import (
+	"chartpaper/internal/db"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type trivyReport struct {
+	ArtifactName string `json:"ArtifactName"`
+	Metadata     struct {
+		RepoDigests []string `json:"RepoDigests"`
+	} `json:"Metadata"`
+	Results []struct {
+		Vulnerabilities []struct {
+			VulnerabilityID  string `json:"VulnerabilityID"`
+			PkgName          string `json:"PkgName"`
+			InstalledVersion string `json:"InstalledVersion"`
+			FixedVersion     string `json:"FixedVersion"`
+			Severity         string `json:"Severity"`
+			Title            string `json:"Title"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+type grypeReport struct {
+	Matches []struct {
+		Vulnerability struct {
+			ID          string `json:"id"`
+			Severity    string `json:"severity"`
+			Description string `json:"description"`
+			Fix         struct {
+				Versions []string `json:"versions"`
+			} `json:"fix"`
+		} `json:"vulnerability"`
+		Artifact struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"artifact"`
+	} `json:"matches"`
+	Source struct {
+		Target struct {
+			UserInput      string   `json:"userInput"`
+			ManifestDigest string   `json:"manifestDigest"`
+			RepoDigests    []string `json:"repoDigests"`
+		} `json:"target"`
+	} `json:"source"`
+}
+
+// ParseScanReport reads a Trivy or Grype JSON report. image overrides the
+// reference recorded in the report and may be a plain digest.
+func ParseScanReport(data []byte, image string) (ScanReport, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return ScanReport{}, fmt.Errorf("invalid report: %v", err)
+	}
+
+	var report ScanReport
+	switch {
+	case probe["matches"] != nil:
+		var grype grypeReport
+		if err := json.Unmarshal(data, &grype); err != nil {
+			return report, fmt.Errorf("invalid grype report: %v", err)
+		}
+		report.Scanner = "grype"
+		report.Image = grype.Source.Target.UserInput
+		report.Digest = grype.Source.Target.ManifestDigest
+		if report.Digest == "" {
+			report.Digest = repoDigest(grype.Source.Target.RepoDigests)
+		}
+		for _, match := range grype.Matches {
+			report.Vulnerabilities = append(report.Vulnerabilities, Vulnerability{
+				ID:               match.Vulnerability.ID,
+				Package:          match.Artifact.Name,
+				InstalledVersion: match.Artifact.Version,
+				FixedVersion:     strings.Join(match.Vulnerability.Fix.Versions, ", "),
+				Severity:         normalizeSeverity(match.Vulnerability.Severity),
+				Title:            match.Vulnerability.Description,
+			})
+		}
+	case probe["Results"] != nil || probe["ArtifactName"] != nil:
+		var trivy trivyReport
+		if err := json.Unmarshal(data, &trivy); err != nil {
+			return report, fmt.Errorf("invalid trivy report: %v", err)
+		}
+		report.Scanner = "trivy"
+		report.Image = trivy.ArtifactName
+		report.Digest = repoDigest(trivy.Metadata.RepoDigests)
+		for _, result := range trivy.Results {
+			for _, vuln := range result.Vulnerabilities {
+				report.Vulnerabilities = append(report.Vulnerabilities, Vulnerability{
+					ID:               vuln.VulnerabilityID,
+					Package:          vuln.PkgName,
+					InstalledVersion: vuln.InstalledVersion,
+					FixedVersion:     vuln.FixedVersion,
+					Severity:         normalizeSeverity(vuln.Severity),
+					Title:            vuln.Title,
+				})
+			}
+		}
+	default:
+		return report, fmt.Errorf("unrecognized report format, expected Trivy or Grype JSON")
+	}
+
+	if image != "" {
+		report.Image = image
+	}
+	if report.Image == "" && report.Digest == "" {
+		return report, fmt.Errorf("report does not name an image, pass ?image=")
+	}
+	for _, vuln := range report.Vulnerabilities {
+		report.Counts.add(vuln.Severity)
+	}
+	return report, nil
+}
+
+// repoDigest returns the digest of the first name@digest entry.
+func repoDigest(repoDigests []string) string {
+	for _, ref := range repoDigests {
+		if i := strings.Index(ref, "@"); i >= 0 {
+			return ref[i+1:]
+		}
+	}
+	return ""
+}
+
+func normalizeSeverity(severity string) string {
+	switch s := strings.ToUpper(strings.TrimSpace(severity)); s {
+	case "CRITICAL", "HIGH", "MEDIUM", "LOW":
+		return s
+	case "NEGLIGIBLE":
+		return "LOW"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+func (c *VulnerabilityCounts) add(severity string) {
+	switch severity {
+	case "CRITICAL":
+		c.Critical++
+	case "HIGH":
+		c.High++
+	case "MEDIUM":
+		c.Medium++
+	case "LOW":
+		c.Low++
+	default:
+		c.Unknown++
+	}
+	c.Total++
+}
+
+// StoreScanReport replaces the findings previously stored for the same image
+// and scanner. Images are matched to charts by registry, repository and tag,
+// or by digest when the report or a digest sync provides one.
+func StoreScanReport(database *pgxpool.Pool, report ScanReport) error {
+	ctx := context.Background()
+	queries := db.New(database)
+
+	params := db.UpsertImageScanParams{Scanner: report.Scanner}
+	digest := report.Digest
+	if strings.HasPrefix(report.Image, "sha256:") {
+		digest = report.Image
+		params.Image = report.Image
+	} else {
+		registry, repository, tag, refDigest := ParseImageReference(report.Image)
+		if refDigest != "" {
+			digest = refDigest
+		}
+		params.Registry = pgtype.Text{String: registry, Valid: true}
+		params.Repository = pgtype.Text{String: repository, Valid: true}
+		params.Tag = pgtype.Text{String: tag, Valid: tag != ""}
+		params.Image = registry + "/" + repository
+		if tag != "" {
+			params.Image += ":" + tag
+		}
+		if refDigest != "" {
+			params.Image += "@" + refDigest
+		}
+	}
+	params.Digest = pgtype.Text{String: digest, Valid: digest != ""}
+
+	scan, err := queries.UpsertImageScan(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to store scan for %s: %v", report.Image, err)
+	}
+	if err := queries.DeleteScanVulnerabilities(ctx, scan.ID); err != nil {
+		return fmt.Errorf("failed to clear previous findings for %s: %v", report.Image, err)
+	}
+	for _, vuln := range report.Vulnerabilities {
+		err := queries.CreateVulnerability(ctx, db.CreateVulnerabilityParams{
+			ScanID:           scan.ID,
+			VulnerabilityID:  vuln.ID,
+			PackageName:      vuln.Package,
+			InstalledVersion: pgtype.Text{String: vuln.InstalledVersion, Valid: vuln.InstalledVersion != ""},
+			FixedVersion:     pgtype.Text{String: vuln.FixedVersion, Valid: vuln.FixedVersion != ""},
+			Severity:         vuln.Severity,
+			Title:            pgtype.Text{String: vuln.Title, Valid: vuln.Title != ""},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to store %s for %s: %v", vuln.ID, report.Image, err)
+		}
+	}
+	return nil
+}
+
+// ChartVulnerabilities holds the counts of one stored chart version and of
+// each of its direct dependencies, keyed by dependency name.
+type ChartVulnerabilities struct {
+	Counts       VulnerabilityCounts
+	Dependencies map[string]*VulnerabilityCounts
+}
+
+// SummarizeVulnerabilities groups findings by chart version. Findings from
+// images a subchart rendered are also credited to the direct dependency that
+// pulled the subchart in, which is the first segment of the subchart path.
+func SummarizeVulnerabilities(rows []db.ListChartVulnerabilitiesRow) map[int32]*ChartVulnerabilities {
+	summaries := map[int32]*ChartVulnerabilities{}
+	for _, row := range rows {
+		summary, ok := summaries[row.ChartID]
+		if !ok {
+			summary = &ChartVulnerabilities{Dependencies: map[string]*VulnerabilityCounts{}}
+			summaries[row.ChartID] = summary
+		}
+		summary.Counts.add(row.Severity)
+
+		if !row.Subchart.Valid || row.Subchart.String == "" {
+			continue
+		}
+		dep := strings.SplitN(row.Subchart.String, "/", 2)[0]
+		counts, ok := summary.Dependencies[dep]
+		if !ok {
+			counts = &VulnerabilityCounts{}
+			summary.Dependencies[dep] = counts
+		}
+		counts.add(row.Severity)
+	}
+	return summaries
+}
+
+// LoadVulnerabilitySummaries loads the findings of chartName's stored versions,
+// or of every chart when chartName is empty.
+func LoadVulnerabilitySummaries(database *pgxpool.Pool, chartName string, includeDisabled bool) (map[int32]*ChartVulnerabilities, error) {
+	queries := db.New(database)
+	rows, err := queries.ListChartVulnerabilities(context.Background(), db.ListChartVulnerabilitiesParams{
+		IncludeDisabled: includeDisabled,
+		ChartName:       pgtype.Text{String: chartName, Valid: chartName != ""},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return SummarizeVulnerabilities(rows), nil
+}
@@ -0,0 +1,301 @@
+package pkg
+
+import (
+	"chartpaper/internal/db"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"helm.sh/helm/v3/pkg/chart"
+	"sigs.k8s.io/yaml"
+)
+
+// AnalyzeDependencyBump renders a stored chart version as it is and with one
+// dependency replaced by another version, and reports what the bump changes.
+// Nothing is stored.
+func AnalyzeDependencyBump(database *pgxpool.Pool, stored db.Chart, dependency, version string) (*WhatIfResult, error) {
+	parent, err := LoadChartURL(database, stored.ChartUrl, stored.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	var dep *chart.Dependency
+	for _, d := range parent.Metadata.Dependencies {
+		if d.Name == dependency || d.Alias == dependency {
+			dep = d
+			break
+		}
+	}
+	if dep == nil {
+		return nil, fmt.Errorf("chart %s %s has no dependency %s", stored.Name, stored.Version, dependency)
+	}
+
+	chartRef, repoURL, err := DependencyChartRef(dep.Repository, dep.Name)
+	if err != nil {
+		return nil, err
+	}
+	candidate, err := LoadChartVersion(database, chartRef, repoURL, version)
+	if err != nil {
+		return nil, err
+	}
+
+	// The dependency as currently resolved, vendored under charts/ or pulled.
+	// It has to be attached before rendering or the baseline would lack it.
+	var current *chart.Chart
+	var subcharts []*chart.Chart
+	for _, sub := range parent.Dependencies() {
+		if sub.Name() == dep.Name {
+			current = sub
+			continue
+		}
+		subcharts = append(subcharts, sub)
+	}
+	if current == nil {
+		if current, err = LoadChartVersion(database, chartRef, repoURL, dep.Version); err != nil {
+			return nil, fmt.Errorf("failed to load current %s %s: %v", dep.Name, dep.Version, err)
+		}
+	}
+
+	// Rendering prunes disabled subcharts and merges import-values into the
+	// parent, so both renders start from the same dependencies and values
+	values := parent.Values
+	kubeVersion := stored.KubeVersion.String
+	apiVersions := DecodeStringList(stored.ApiVersions)
+	parent.SetDependencies(append(subcharts, current)...)
+	before, err := renderChart(parent, nil, stored.Name, "", kubeVersion, apiVersions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s %s: %v", stored.Name, stored.Version, err)
+	}
+
+	result := &WhatIfResult{
+		Chart:         stored.Name,
+		Version:       stored.Version,
+		Dependency:    dep.Name,
+		FromVersion:   current.Metadata.Version,
+		ToVersion:     candidate.Metadata.Version,
+		ValuesChanges: DiffValues(current.Values, candidate.Values),
+	}
+
+	parent.Values = values
+	parent.SetDependencies(append(subcharts, candidate)...)
+	metadataDeps := make([]*chart.Dependency, 0, len(parent.Metadata.Dependencies))
+	chartDeps := make([]Dependency, 0, len(parent.Metadata.Dependencies))
+	for _, d := range parent.Metadata.Dependencies {
+		copied := *d
+		if d == dep {
+			copied.Version = candidate.Metadata.Version
+		}
+		metadataDeps = append(metadataDeps, &copied)
+		chartDeps = append(chartDeps, Dependency{
+			Name:       copied.Name,
+			Alias:      copied.Alias,
+			Version:    copied.Version,
+			Repository: copied.Repository,
+			Condition:  copied.Condition,
+			Tags:       copied.Tags,
+		})
+	}
+	parent.Metadata.Dependencies = metadataDeps
+
+	after, err := renderChart(parent, nil, stored.Name, "", kubeVersion, apiVersions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s %s with %s %s: %v", stored.Name, stored.Version, dep.Name, version, err)
+	}
+
+	result.ManifestChanges = DiffManifests(before.Manifest, after.Manifest)
+	result.ImageChanges = DiffImages(ExtractContainerImages(before.Manifest), ExtractContainerImages(after.Manifest))
+
+	chartInfo := ChartInfo{
+		Chart: Chart{
+			APIVersion:   parent.Metadata.APIVersion,
+			Name:         stored.Name,
+			Version:      stored.Version,
+			Type:         stored.Type,
+			Dependencies: chartDeps,
+		},
+		Manifest: after.Manifest,
+	}
+	violations, _, err := EvaluatePolicies(database, chartInfo)
+	if err != nil {
+		result.Warnings = append(result.Warnings, err.Error())
+	}
+	result.Violations = violations
+	result.Denied = HasDenyViolation(violations)
+
+	if result.ValuesChanges == nil {
+		result.ValuesChanges = []ValuesKeyChange{}
+	}
+	if result.Violations == nil {
+		result.Violations = []PolicyViolation{}
+	}
+	return result, nil
+}
+
+// DiffManifests compares two rendered manifests resource by resource. Each
+// resource is normalized to sorted YAML first so formatting doesn't count.
+func DiffManifests(before, after string) []ResourceChange {
+	beforeDocs := normalizedResources(before)
+	afterDocs := normalizedResources(after)
+
+	changes := []ResourceChange{}
+	for key, doc := range beforeDocs {
+		afterDoc, ok := afterDocs[key]
+		switch {
+		case !ok:
+			changes = append(changes, ResourceChange{Resource: key, Change: "removed", Diff: lineDiff(doc, "")})
+		case afterDoc != doc:
+			changes = append(changes, ResourceChange{Resource: key, Change: "changed", Diff: lineDiff(doc, afterDoc)})
+		}
+	}
+	for key, doc := range afterDocs {
+		if _, ok := beforeDocs[key]; !ok {
+			changes = append(changes, ResourceChange{Resource: key, Change: "added", Diff: lineDiff("", doc)})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Resource < changes[j].Resource
+	})
+	return changes
+}
+
+func normalizedResources(manifest string) map[string]string {
+	docs := map[string]string{}
+	for _, resource := range decodeManifest(manifest) {
+		key := resource.Kind() + "/" + resource.Name()
+		if namespace := nestedString(resource.Object, "metadata", "namespace"); namespace != "" {
+			key = resource.Kind() + "/" + namespace + "/" + resource.Name()
+		}
+		data, err := yaml.Marshal(resource.Object)
+		if err != nil {
+			continue
+		}
+		docs[key] = string(data)
+	}
+	return docs
+}
+
+// lineDiff returns a unified style diff of two texts with three lines of
+// context around each change.
+func lineDiff(before, after string) string {
+	a := splitLines(before)
+	b := splitLines(after)
+
+	// lcs[i][j] is the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, " "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "-"+a[i])
+			i++
+		default:
+			lines = append(lines, "+"+b[j])
+			j++
+		}
+	}
+
+	const context = 3
+	keep := make([]bool, len(lines))
+	for n, line := range lines {
+		if line[0] == ' ' {
+			continue
+		}
+		for k := max(0, n-context); k <= min(len(lines)-1, n+context); k++ {
+			keep[k] = true
+		}
+	}
+	var out strings.Builder
+	skipped := false
+	for n, line := range lines {
+		if !keep[n] {
+			skipped = true
+			continue
+		}
+		if skipped && out.Len() > 0 {
+			out.WriteString("@@\n")
+		}
+		skipped = false
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	return out.String()
+}
+
+func splitLines(text string) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// DiffImages pairs the containers of two renders by workload and container
+// name and reports those whose image changed, appeared or disappeared.
+func DiffImages(before, after []ContainerImage) []ImageChange {
+	key := func(image ContainerImage) string {
+		return containerResource(image.WorkloadKind, image.Workload, image.Container)
+	}
+	beforeImages := map[string]ContainerImage{}
+	for _, image := range before {
+		beforeImages[key(image)] = image
+	}
+
+	changes := []ImageChange{}
+	seen := map[string]bool{}
+	for _, image := range after {
+		k := key(image)
+		seen[k] = true
+		previous, ok := beforeImages[k]
+		if ok && previous.Image == image.Image {
+			continue
+		}
+		change := ImageChange{
+			Workload:     image.Workload,
+			WorkloadKind: image.WorkloadKind,
+			Container:    image.Container,
+			To:           image.Image,
+		}
+		if ok {
+			change.From = previous.Image
+		}
+		changes = append(changes, change)
+	}
+	for k, image := range beforeImages {
+		if !seen[k] {
+			changes = append(changes, ImageChange{
+				Workload:     image.Workload,
+				WorkloadKind: image.WorkloadKind,
+				Container:    image.Container,
+				From:         image.Image,
+			})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return containerResource(changes[i].WorkloadKind, changes[i].Workload, changes[i].Container) <
+			containerResource(changes[j].WorkloadKind, changes[j].Workload, changes[j].Container)
+	})
+	return changes
+}
+
+func containerResource(workloadKind, workload, container string) string {
+	return fmt.Sprintf("%s/%s/%s", workloadKind, workload, container)
+}
@@ -1,5 +1,5 @@
 import { useEffect, useRef, useState } from 'react'
-import { ChartInfo, Node, Edge } from '../types'
+import { ChartInfo, Node, Edge, SecuritySummary } from '../types'
 import { Badge } from './ui/badge'
 import { Button } from './ui/button'
 import { Package, Download, Plus, X, ChevronDown, History, ZoomIn, ZoomOut, Maximize2 } from 'lucide-react'
@@ -99,7 +99,8 @@ export function ChartVisualizer({ charts, selectedChart, onChartSelect, onFetchD
         y: y,
         dependencies: chartInfo.chart.dependencies?.map(d => d.name) || [],
         expanded: expandedCharts.has(chartInfo.chart.name),
-        isRoot: true
+        isRoot: true,
+        security: chartInfo.security
       })
 
       // If chart is expanded, add dependency nodes
@@ -137,7 +138,8 @@ export function ChartVisualizer({ charts, selectedChart, onChartSelect, onFetchD
             y: depY,
             dependencies: [],
             expanded: false,
-            isRoot: false
+            isRoot: false,
+            security: dep.security
           })
 
           // Create edge from parent to dependency
@@ -764,6 +766,7 @@ export function ChartVisualizer({ charts, selectedChart, onChartSelect, onFetchD
                               {node.canaryTag}
                             </Badge>
                           )}
+                          {node.security && <SecurityBadge security={node.security} />}
                         </div>
                         
                         {/* Container images from manifest metadata */}
@@ -893,7 +896,10 @@ export function ChartVisualizer({ charts, selectedChart, onChartSelect, onFetchD
                         <div className="w-2 h-2 rounded-full bg-purple-500"></div>
                         <h4 className="font-medium text-xs truncate">{node.name}</h4>
                       </div>
-                      <Badge variant="secondary" className="text-xs">v{node.version}</Badge>
+                      <div className="flex gap-1">
+                        <Badge variant="secondary" className="text-xs">v{node.version}</Badge>
+                        {node.security && <SecurityBadge security={node.security} />}
+                      </div>
                       
                       <div className="space-y-1 text-xs">
                         <div className="flex items-center gap-1">
@@ -1010,4 +1016,20 @@ export function ChartVisualizer({ charts, selectedChart, onChartSelect, onFetchD
       </div>
     </div>
   )
-}
\ No newline at end of file
+}
+
+// Risk score from the security posture of the rendered manifest
+function SecurityBadge({ security }: { security: SecuritySummary }) {
+  const variant = security.level === 'critical' || security.level === 'high'
+    ? 'destructive'
+    : security.level === 'medium' ? 'secondary' : 'outline'
+  return (
+    <Badge
+      variant={variant}
+      className="text-xs px-1 py-0"
+      title={`${security.findings} security findings, ${security.level} risk`}
+    >
+      risk {security.score}
+    </Badge>
+  )
+}
@@ -1,8 +1,16 @@
+export interface SecuritySummary {
+  score: number
+  level: 'low' | 'medium' | 'high' | 'critical'
+  findings: number
+}
+
 export interface Dependency {
   name: string
+  alias?: string
   version: string
   repository: string
   condition?: string
+  security?: SecuritySummary
 }
 
 export interface Chart {
@@ -27,6 +35,7 @@ export interface ChartInfo {
   imageTag: string
   canaryTag: string
   manifestMetadata?: ManifestMetadata
+  security?: SecuritySummary
 }
 
 export interface Node {
@@ -41,6 +50,7 @@ export interface Node {
   dependencies: string[]
   expanded: boolean
   isRoot: boolean
+  security?: SecuritySummary
 }
 
 export interface Edge {